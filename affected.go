@@ -0,0 +1,29 @@
+package mysql
+
+// Same api with `Update(...)` method except it returns the number of affected 
+// rows, and errors instead of panicking. Rows which already had the new 
+// values are not counted as affected, unless `Config.ClientFoundRows` is set, 
+// then every matched row is counted.
+//
+// Example:
+//   n, err := mysql.UpdateCount("users", _json{"status": "active"}, _json{"id": id})
+//   if err == nil && n == 0 { return ErrUnknownUser } // with ClientFoundRows
+func (d *DB) UpdateCount(
+  table string,
+  data, where map[string]interface{},
+  options ...map[string]interface{},
+) (count int64, err error) {
+  defer RecoverError(&err)
+  return d.Update(table, data, where, options...).RowsAffected()
+}
+
+// Same api with `Delete(...)` method except it returns the number of deleted 
+// rows, and errors instead of panicking.
+func (d *DB) DeleteCount(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (count int64, err error) {
+  defer RecoverError(&err)
+  return d.Delete(table, where, options...).RowsAffected()
+}
@@ -0,0 +1,127 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Counts rows of a table matching the `where` conditions.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   count, err := mysql.Count("orders", _json{"status": "pending"})
+func (d *DB) Count(table string, where map[string]interface{}) (count int64, err error) {
+  defer RecoverError(&err)
+  w := prepare_where(model_where(table, where, nil))
+  from  := escape_tenant_table(table, TenantFromContext(d.context()))
+  query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s;", from, w.query)
+  err = d.scan_row([]interface{}{&count}, query, w.values...)
+  return count, err
+}
+
+// Returns `SUM(column)` of rows matching the `where` conditions, 0 when no row 
+// matches. Sums of integer columns above 2^53 lose precision, see 
+// `SumInt64(...)`.
+func (d *DB) Sum(table, column string, where map[string]interface{}) (float64, error) {
+  return d.aggregate("SUM", table, column, where)
+}
+
+// Returns `SUM(column)` of an integer column without losing precision, 0 when 
+// no row matches.
+func (d *DB) SumInt64(table, column string, where map[string]interface{}) (int64, error) {
+  var value sql.NullInt64
+  err := d.aggregate_into(&value, "SUM", table, column, where)
+  return value.Int64, err
+}
+
+// Returns `MIN(column)` of a numeric column of rows matching the `where` 
+// conditions, 0 when no row matches. See `MinTime(...)` and `MinString(...)` 
+// for other columns.
+func (d *DB) Min(table, column string, where map[string]interface{}) (float64, error) {
+  return d.aggregate("MIN", table, column, where)
+}
+
+// Returns `MAX(column)` of a numeric column of rows matching the `where` 
+// conditions, 0 when no row matches. See `MaxTime(...)` and `MaxString(...)` 
+// for other columns.
+func (d *DB) Max(table, column string, where map[string]interface{}) (float64, error) {
+  return d.aggregate("MAX", table, column, where)
+}
+
+// Returns `MIN(column)` of a DATE, DATETIME or TIMESTAMP column, the zero 
+// time when no row matches.
+func (d *DB) MinTime(table, column string, where map[string]interface{}) (time.Time, error) {
+  return d.aggregate_time("MIN", table, column, where)
+}
+
+// Returns `MAX(column)` of a DATE, DATETIME or TIMESTAMP column, the zero 
+// time when no row matches.
+func (d *DB) MaxTime(table, column string, where map[string]interface{}) (time.Time, error) {
+  return d.aggregate_time("MAX", table, column, where)
+}
+
+// Returns `MIN(column)` of a text column, "" when no row matches.
+func (d *DB) MinString(table, column string, where map[string]interface{}) (string, error) {
+  var value sql.NullString
+  err := d.aggregate_into(&value, "MIN", table, column, where)
+  return value.String, err
+}
+
+// Returns `MAX(column)` of a text column, "" when no row matches.
+func (d *DB) MaxString(table, column string, where map[string]interface{}) (string, error) {
+  var value sql.NullString
+  err := d.aggregate_into(&value, "MAX", table, column, where)
+  return value.String, err
+}
+
+// Returns `AVG(column)` of rows matching the `where` conditions, 0 when no row 
+// matches.
+func (d *DB) Avg(table, column string, where map[string]interface{}) (float64, error) {
+  return d.aggregate("AVG", table, column, where)
+}
+
+func (d *DB) aggregate(
+  function, table, column string,
+  where map[string]interface{},
+) (float64, error) {
+  var value sql.NullFloat64
+  err := d.aggregate_into(&value, function, table, column, where)
+  return value.Float64, err
+}
+
+// Times are `time.Time` with `Config.ParseTime`, text otherwise.
+func (d *DB) aggregate_time(
+  function, table, column string,
+  where map[string]interface{},
+) (time.Time, error) {
+  var value interface{}
+  if err := d.aggregate_into(&value, function, table, column, where); err != nil {
+    return time.Time{}, err
+  }
+  switch value := value.(type) {
+  case nil:
+    return time.Time{}, nil
+  case time.Time:
+    return value, nil
+  case []byte:
+    if t, ok := parse_datetime(string(value)); ok { return t, nil }
+  }
+  return time.Time{}, fmt.Errorf("mysql: %s(%s) is not a time: %v", function, column, value)
+}
+
+// Scans the result of an aggregate function into `dest`.
+func (d *DB) aggregate_into(
+  dest interface{},
+  function, table, column string,
+  where map[string]interface{},
+) (err error) {
+  defer RecoverError(&err)
+  w := prepare_where(model_where(table, where, nil))
+  from  := escape_tenant_table(table, TenantFromContext(d.context()))
+  args  := []interface{}{ function, EscapeId(column), from, w.query }
+  query := fmt.Sprintf("SELECT %s(%s) FROM %s%s;", args...)
+  return d.scan_row([]interface{}{dest}, query, w.values...)
+}
+
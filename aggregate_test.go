@@ -0,0 +1,30 @@
+package mysql_test
+
+import (
+	"testing"
+	"time"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestTypedAggregates(t *testing.T) {
+  mock := mysqltest.New(t)
+  mock.On("SUM(`total`)").Rows([]string{"sum"}, []interface{}{"9007199254740993"})
+  mock.On("MAX(`created_at`)").Rows([]string{"max"}, []interface{}{"2024-01-02 03:04:05"})
+  mock.On("MIN(`name`)").Rows([]string{"min"}, []interface{}{"alice"})
+  mock.On("MIN(`updated_at`)").Rows([]string{"min"}, []interface{}{nil})
+
+  sum, err := mysql.SumInt64("orders", "total", nil)
+  if err != nil || sum != 9007199254740993 { t.Fatalf("SumInt64 = %d, %v", sum, err) }
+
+  latest, err := mysql.MaxTime("orders", "created_at", nil)
+  want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+  if err != nil || !latest.Equal(want) { t.Fatalf("MaxTime = %v, %v", latest, err) }
+
+  name, err := mysql.MinString("users", "name", nil)
+  if err != nil || name != "alice" { t.Fatalf("MinString = %q, %v", name, err) }
+
+  none, err := mysql.MinTime("orders", "updated_at", nil)
+  if err != nil || !none.IsZero() { t.Fatalf("MinTime of no rows = %v, %v", none, err) }
+}
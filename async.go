@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Maximum number of asynchronous queries running at the same time, others 
+// wait for a free slot. Set it before the first asynchronous query, and below 
+// `Config.MaxOpenConns` so synchronous queries still get connections.
+var MaxAsyncQueries = 8
+
+var async_slots struct {
+  once  sync.Once
+  slots chan struct{}
+}
+
+// Result of an asynchronous query, see `Async(...)`.
+type Future[T any] struct {
+  done  chan struct{}
+  value T
+  err   error
+}
+
+// Waits for the query and returns its result.
+func (f *Future[T]) Wait() (T, error) {
+  <-f.done
+  return f.value, f.err
+}
+
+// Returns a channel closed when the query finished, to wait in a select 
+// statement.
+func (f *Future[T]) Done() <-chan struct{} { return f.done }
+
+// Runs `fn` in a goroutine limited by `MaxAsyncQueries`, and returns its 
+// future result. A panic of `fn` with an error, like the panics of this 
+// package, is returned as the error of the result.
+//
+// Example:
+//   total := mysql.Async(func() (int64, error) { return mysql.Count("orders", nil) })
+//   users := mysql.SelectAsync("users", _json{"status": "active"})
+//   count, err := total.Wait()
+//   rows,  err := users.Wait()
+func Async[T any](fn func() (T, error)) *Future[T] {
+  return async_call(std, fn)
+}
+
+func async_call[T any](d *DB, fn func() (T, error)) *Future[T] {
+  future := &Future[T]{done: make(chan struct{})}
+  go func() {
+    defer close(future.done)
+    defer RecoverError(&future.err)
+    if err := d.acquire_async_slot(); err != nil { panic(err) }
+    defer func() { <-async_slots.slots }()
+    future.value, future.err = fn()
+  }()
+  return future
+}
+
+// Waits for a free slot of the asynchronous queries, or for the end of the 
+// context of the handle.
+func (d *DB) acquire_async_slot() error {
+  async_slots.once.Do(func() {
+    size := MaxAsyncQueries
+    if size < 1 { size = 1 }
+    async_slots.slots = make(chan struct{}, size)
+  })
+  select {
+  case async_slots.slots <- struct{}{}:
+    return nil
+  case <-d.context().Done():
+    return d.context().Err()
+  }
+}
+
+// Same api with `Select(...)` method except the query runs asynchronously, so 
+// queries of several tables, e.g. of a dashboard, run concurrently. Handles 
+// of a transaction or of a dedicated connection can't run asynchronous 
+// queries, their connection runs one query at a time.
+//
+// Example:
+//   orders := mysql.SelectAsync("orders", _json{"status": "pending"})
+//   users  := mysql.SelectAsync("users", _json{"status": "active"})
+//   pending, err := orders.Wait()
+//   active,  err := users.Wait()
+func (d *DB) SelectAsync(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) *Future[[]map[string]interface{}] {
+  d.check_async()
+  return async_call(d, func() ([]map[string]interface{}, error) {
+    return d.Select(table, where, options...), nil
+  })
+}
+
+// Same api with `Exec(...)` method except the query runs asynchronously, see 
+// `SelectAsync(...)`.
+func (d *DB) ExecAsync(query string, values ...interface{}) *Future[sql.Result] {
+  d.check_async()
+  return async_call(d, func() (sql.Result, error) {
+    return d.Exec(query, values...), nil
+  })
+}
+
+func (d *DB) check_async() {
+  if d.tx != nil || d.conn != nil {
+    panic(errors.New("mysql: asynchronous queries can't run on a transaction or connection handle"))
+  }
+}
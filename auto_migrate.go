@@ -0,0 +1,284 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Creates the tables of struct models, and adds their missing columns and 
+// indexes to existing tables. Existing columns are never changed or dropped, 
+// so it's meant for development and small services, use versioned 
+// migrations for anything else.
+//
+// The table name is the result of a `TableName() string` method, or the snake 
+// case of the type name. Columns are the exported fields, named like in 
+// `WhereFromStruct(...)`: the `db` tag, or the snake case of the field name, 
+// fields tagged `db:"-"` are skipped and embedded structs are flattened.
+//
+// The column type follows the Go type, e.g. BINARY(16) for `UUID`, pointers 
+// and `sql.Null*` types are nullable, and the `mysql` tag holds options 
+// separated by ";":
+//   - `type:DECIMAL(10,2)`: column type instead of the one of the Go type
+//   - `size:100`: length of strings, VARCHAR(255) by default
+//   - `null`, `not null`: nullability instead of the one of the Go type
+//   - `default:0`: default value, written as is
+//   - `primary`: part of the primary key, a field "id" is by default. A 
+//                single integer primary key is AUTO_INCREMENT
+//   - `index`, `unique`: single column index, or `index:name` and 
+//                        `unique:name` for an index of every column with
+//                        the same name
+//
+// Example:
+//   type User struct {
+//     ID        int64
+//     Email     string     `mysql:"size:191;unique"`
+//     Name      string     `mysql:"size:100"`
+//     Balance   float64    `mysql:"type:DECIMAL(10,2);default:0"`
+//     TenantID  int64      `mysql:"index:idx_tenant_created"`
+//     CreatedAt time.Time  `mysql:"index:idx_tenant_created"`
+//     DeletedAt *time.Time
+//   }
+//
+//   // CREATE TABLE IF NOT EXISTS `user` (`id` BIGINT NOT NULL AUTO_INCREMENT,
+//   //   `email` VARCHAR(191) NOT NULL, ..., PRIMARY KEY (`id`),
+//   //   UNIQUE KEY `uniq_user_email` (`email`),
+//   //   KEY `idx_tenant_created` (`tenant_id`, `created_at`))
+//   if err := mysql.AutoMigrate(User{}); err != nil { log.Fatal(err) }
+func AutoMigrate(models ...interface{}) (err error) {
+  defer RecoverError(&err)
+  for _, model := range models {
+    definition := table_definition(model)
+    InvalidateTable(definition.table)
+    if existing := Columns(definition.table); len(existing) == 0 {
+      Exec(definition.create())
+    } else if query := definition.alter(existing, Indexes(definition.table)); query != "" {
+      Exec(query)
+    }
+    InvalidateTable(definition.table)
+  }
+  return nil
+}
+
+type auto_table struct {
+  table   string
+  columns []auto_column
+  primary []string
+  indexes []*auto_index
+}
+
+type auto_column struct {
+  name       string
+  definition string
+}
+
+type auto_index struct {
+  name    string
+  unique  bool
+  columns []string
+}
+
+var (
+  time_type  = reflect.TypeOf(time.Time{})
+  bytes_type = reflect.TypeOf([]byte(nil))
+  uuid_type  = reflect.TypeOf(UUID{})
+  null_types = map[reflect.Type]reflect.Type{
+    reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+    reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+    reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+    reflect.TypeOf(sql.NullInt16{}):   reflect.TypeOf(int16(0)),
+    reflect.TypeOf(sql.NullByte{}):    reflect.TypeOf(uint8(0)),
+    reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+    reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+    reflect.TypeOf(sql.NullTime{}):    time_type,
+  }
+)
+
+func table_definition(model interface{}) *auto_table {
+  t := reflect.TypeOf(model)
+  for t != nil && t.Kind() == reflect.Pointer { t = t.Elem() }
+  if t == nil || t.Kind() != reflect.Struct {
+    panic(fmt.Errorf("mysql: AutoMigrate expects a struct, got %T", model))
+  }
+
+  definition := &auto_table{table: snake_case(t.Name())}
+  if named, ok := model.(interface{ TableName() string }); ok {
+    definition.table = named.TableName()
+  }
+  definition.add_fields(t)
+  if len(definition.columns) == 0 {
+    panic(fmt.Errorf("mysql: model %s has no columns", t))
+  }
+  if len(definition.primary) == 0 {
+    for _, column := range definition.columns {
+      if column.name == "id" { definition.primary = []string{"id"} }
+    }
+  }
+  if len(definition.primary) == 1 {
+    for i, column := range definition.columns {
+      if column.name == definition.primary[0] && is_integer_type(column.definition) {
+        definition.columns[i].definition += " AUTO_INCREMENT"
+      }
+    }
+  }
+  return definition
+}
+
+func (a *auto_table) add_fields(t reflect.Type) {
+  for i := 0; i < t.NumField(); i++ {
+    field := t.Field(i)
+    name  := ""
+    if fields := strings.Fields(field.Tag.Get("db")); len(fields) > 0 { name = fields[0] }
+    if name == "-" { continue }
+    if field.Anonymous && name == "" {
+      embedded := field.Type
+      if embedded.Kind() == reflect.Pointer { embedded = embedded.Elem() }
+      if embedded.Kind() == reflect.Struct && embedded != time_type {
+        a.add_fields(embedded)
+        continue
+      }
+    }
+    if !field.IsExported() { continue }
+    if name == "" { name = snake_case(field.Name) }
+
+    options := map[string]string{}
+    for _, option := range strings.Split(field.Tag.Get("mysql"), ";") {
+      key, value, _ := strings.Cut(strings.TrimSpace(option), ":")
+      if key != "" { options[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value) }
+    }
+
+    column_type, nullable := auto_column_type(field.Type, options)
+    if _, ok := options["null"]; ok { nullable = true }
+    if _, ok := options["not null"]; ok { nullable = false }
+    _, primary := options["primary"]
+    if primary {
+      nullable  = false
+      a.primary = append(a.primary, name)
+    }
+
+    definition := EscapeId(name, true) + " " + column_type
+    if nullable { definition += " NULL" } else { definition += " NOT NULL" }
+    if value, ok := options["default"]; ok { definition += " DEFAULT " + value }
+    a.columns = append(a.columns, auto_column{name, definition})
+
+    if index, ok := options["index"]; ok { a.add_index(index, false, name) }
+    if index, ok := options["unique"]; ok { a.add_index(index, true, name) }
+  }
+}
+
+func (a *auto_table) add_index(name string, unique bool, column string) {
+  if name == "" {
+    prefix := "idx_"
+    if unique { prefix = "uniq_" }
+    name = prefix + a.table + "_" + column
+  }
+  for _, index := range a.indexes {
+    if index.name == name {
+      index.columns = append(index.columns, column)
+      return
+    }
+  }
+  a.indexes = append(a.indexes, &auto_index{name: name, unique: unique, columns: []string{column}})
+}
+
+// Returns the column type of a Go type, and whether it's nullable.
+func auto_column_type(t reflect.Type, options map[string]string) (string, bool) {
+  nullable := false
+  if t.Kind() == reflect.Pointer {
+    t, nullable = t.Elem(), true
+  }
+  if base, ok := null_types[t]; ok { t, nullable = base, true }
+  if column_type, ok := options["type"]; ok { return column_type, nullable }
+
+  size := 0
+  if value, ok := options["size"]; ok {
+    var err error
+    if size, err = strconv.Atoi(value); err != nil || size < 1 {
+      panic(fmt.Errorf("mysql: invalid column size %q", value))
+    }
+  }
+
+  switch {
+  case t == time_type:
+    return "DATETIME", nullable
+  case t == uuid_type:
+    return "BINARY(16)", nullable
+  case t == bytes_type || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+    if size > 0 { return fmt.Sprintf("VARBINARY(%d)", size), nullable }
+    return "BLOB", nullable
+  }
+
+  switch t.Kind() {
+  case reflect.Bool:                 return "TINYINT(1)", nullable
+  case reflect.Int8:                 return "TINYINT", nullable
+  case reflect.Int16:                return "SMALLINT", nullable
+  case reflect.Int32:                return "INT", nullable
+  case reflect.Int, reflect.Int64:   return "BIGINT", nullable
+  case reflect.Uint8:                return "TINYINT UNSIGNED", nullable
+  case reflect.Uint16:               return "SMALLINT UNSIGNED", nullable
+  case reflect.Uint32:               return "INT UNSIGNED", nullable
+  case reflect.Uint, reflect.Uint64: return "BIGINT UNSIGNED", nullable
+  case reflect.Float32:              return "FLOAT", nullable
+  case reflect.Float64:              return "DOUBLE", nullable
+  case reflect.String:
+    if size == 0 { size = 255 }
+    if size > 16383 { return "TEXT", nullable }
+    return fmt.Sprintf("VARCHAR(%d)", size), nullable
+  case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+    return "JSON", nullable
+  }
+  panic(fmt.Errorf("mysql: unsupported column type %s", t))
+}
+
+func is_integer_type(definition string) bool {
+  fields := strings.Fields(definition)
+  return len(fields) > 1 && strings.HasSuffix(fields[1], "INT")
+}
+
+func (a *auto_table) create() string {
+  clauses := make([]string, 0, len(a.columns)+len(a.indexes)+1)
+  for _, column := range a.columns { clauses = append(clauses, column.definition) }
+  if len(a.primary) > 0 { clauses = append(clauses, "PRIMARY KEY " + index_columns(a.primary)) }
+  for _, index := range a.indexes { clauses = append(clauses, index.definition()) }
+
+  return fmt.Sprintf(
+    "CREATE TABLE IF NOT EXISTS %s (%s) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+    EscapeId(a.table), strings.Join(clauses, ", "),
+  )
+}
+
+// Returns the ALTER TABLE query adding missing columns and indexes, or "".
+func (a *auto_table) alter(columns []Column, indexes []Index) string {
+  existing := map[string]bool{}
+  for _, column := range columns { existing[strings.ToLower(column.Name)] = true }
+  for _, index := range indexes  { existing["index "+strings.ToLower(index.Name)] = true }
+
+  var clauses []string
+  for _, column := range a.columns {
+    if !existing[strings.ToLower(column.name)] {
+      clauses = append(clauses, "ADD COLUMN "+column.definition)
+    }
+  }
+  for _, index := range a.indexes {
+    if !existing["index "+strings.ToLower(index.name)] {
+      clauses = append(clauses, "ADD "+index.definition())
+    }
+  }
+  if len(clauses) == 0 { return "" }
+  return fmt.Sprintf("ALTER TABLE %s %s;", EscapeId(a.table), strings.Join(clauses, ", "))
+}
+
+func (i *auto_index) definition() string {
+  kind := "KEY "
+  if i.unique { kind = "UNIQUE KEY " }
+  return kind + EscapeId(i.name, true) + " " + index_columns(i.columns)
+}
+
+func index_columns(columns []string) string {
+  escaped := make([]string, len(columns))
+  for i, column := range columns { escaped[i] = EscapeId(column, true) }
+  return "(" + strings.Join(escaped, ", ") + ")"
+}
@@ -0,0 +1,110 @@
+package mysql
+
+import (
+	"fmt"
+	"time"
+)
+
+// Iterates rows of a table matching the `where` conditions in batches ordered 
+// by the primary key. Batches are read with keyset pagination 
+// (`WHERE id > last_id ORDER BY id LIMIT n`), so every batch is as fast as the 
+// first one, unlike OFFSET pagination. Use it for backfill and export jobs 
+// over large tables. The table must have a single-column primary key.
+//
+// Iteration stops at the first error returned by `fn`, which is returned.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   err := mysql.SelectInBatches("users", _json{"active": 1}, 1000,
+//     func(rows []map[string]interface{}) error {
+//       return export(rows)
+//     },
+//   )
+func (d *DB) SelectInBatches(
+  table string,
+  where map[string]interface{},
+  batch_size int,
+  fn func(rows []map[string]interface{}) error,
+) error {
+  if batch_size < 1 {
+    panic(fmt.Errorf("mysql: invalid batch size %d", batch_size))
+  }
+  key := d.primary_key(table)
+
+  conditions := map[string]interface{}{}
+  for column, value := range where { conditions[column] = value }
+  options := map[string]interface{}{
+    "order": EscapeId(key) + " ASC",
+    "limit": batch_size,
+  }
+
+  for {
+    rows := d.Select(table, conditions, options)
+    if len(rows) == 0 { return nil }
+    if err := fn(rows); err != nil { return err }
+    if len(rows) < batch_size { return nil }
+    conditions[key + " >"] = rows[len(rows)-1][key]
+  }
+}
+
+// Deletes rows matching the `where` conditions with `DELETE ... LIMIT n` 
+// until no row is deleted, so retention jobs purging millions of rows don't 
+// hold locks for long or write a huge transaction to the binlog. Hooks run for 
+// every batch. Don't run it inside a transaction, which holds the locks of 
+// every batch until it ends.
+//
+// Options:
+//   - `sleep`: time.Duration, pause between batches, e.g. to let replicas 
+//              catch up
+//   - `order`, `force`: options of `Delete(...)`
+// Returns:
+//   - int64: number of deleted rows, also of the batches deleted before an 
+//            error
+//   - error: error of the failing batch
+//
+// Example:
+//   deleted, err := mysql.DeleteInBatches("events", _json{
+//     "created_at <": time.Now().AddDate(0, -6, 0),
+//   }, 5000, _json{"sleep": 100 * time.Millisecond})
+func (d *DB) DeleteInBatches(
+  table string,
+  where map[string]interface{},
+  batch_size int,
+  args ...map[string]interface{},
+) (int64, error) {
+  if batch_size < 1 {
+    panic(fmt.Errorf("mysql: invalid batch size %d", batch_size))
+  }
+  options := map[string]interface{}{}
+  if len(args) > 0 {
+    for key, value := range args[0] { options[key] = value }
+  }
+  sleep, _ := options["sleep"].(time.Duration)
+  delete(options, "sleep")
+  options["limit"] = batch_size
+
+  var total int64
+  for {
+    count, err := d.DeleteCount(table, where, options)
+    total += count
+    if err != nil || count < int64(batch_size) { return total, err }
+    if sleep > 0 { time.Sleep(sleep) }
+  }
+}
+
+// Returns the name of the single-column primary key of a table, from its 
+// model when it's registered, or from the schema of the table of the 
+// handle's tenant.
+func (d *DB) primary_key(table string) string {
+  if model := ModelOf(table); model != nil { return model.PrimaryKey }
+
+  var keys []string
+  for _, column := range d.Columns(d.tenant_table(table)) {
+    if column.Key == "PRI" { keys = append(keys, column.Name) }
+  }
+  if len(keys) != 1 {
+    panic(fmt.Errorf("mysql: table %q has no single-column primary key", table))
+  }
+  return keys[0]
+}
@@ -0,0 +1,74 @@
+package mysql
+
+// Returns the query and values `Select(...)` would run, without running it. 
+// Useful to assert on generated queries in tests, or to inspect them.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   query, values := mysql.BuildSelect("users", _json{"age >": 18}, _json{"limit": 10})
+//   // query:  SELECT * FROM `users` WHERE `age` > ? LIMIT 0, 10;
+//   // values: [18]
+func BuildSelect(
+  table string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) (string, []interface{}) {
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+  return std.build_select_query(table, where, options)
+}
+
+// Returns the query and values `Insert(...)` would run, without running it. 
+// Defaults of `RegisterDefaults(...)` and the `Model` of the table apply.
+func BuildInsert(table string, data map[string]interface{}) (string, []interface{}) {
+  return build_insert("INSERT INTO", table, model_data(table, data, true))
+}
+
+// Returns the query and values `Update(...)` would run, without running it.
+func BuildUpdate(
+  table string,
+  data, where map[string]interface{},
+  args ...map[string]interface{},
+) (string, []interface{}) {
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+  return build_update(table, data, where, options)
+}
+
+// Returns the query and values `Delete(...)` would run, without running it. 
+// It's an UPDATE query for tables with soft deletes, see `Model`.
+func BuildDelete(
+  table string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) (string, []interface{}) {
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+  return build_delete(table, where, options)
+}
+
+// Returns the query and values `Select()` would run, see `BuildSelect(...)`.
+func (q *Query) BuildSelect() (string, []interface{}) {
+  return q.handle.build_select_query(q.table, q.where, q.options)
+}
+
+// Returns the query and values `Update(data)` would run, see 
+// `BuildUpdate(...)`.
+func (q *Query) BuildUpdate(data map[string]interface{}) (string, []interface{}) {
+  return BuildUpdate(q.table, data, q.where, q.options)
+}
+
+// Returns the query and values `Delete()` would run, see `BuildDelete(...)`.
+func (q *Query) BuildDelete() (string, []interface{}) {
+  return BuildDelete(q.table, q.where, q.options)
+}
+
+func (d *DB) build_select_query(
+  table string,
+  where, options map[string]interface{},
+) (string, []interface{}) {
+  options = d.select_options(options)
+  query, values := build_select(prepare_columns(options), table, where, options, true)
+  return query + ";", values
+}
@@ -0,0 +1,175 @@
+package mysql_test
+
+import (
+	"reflect"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+func TestBuildSelectConditions(t *testing.T) {
+  tests := []struct {
+    name   string
+    where  map[string]interface{}
+    query  string
+    values []interface{}
+  }{
+    {"equal", map[string]interface{}{"id": 1}, "`id` = ?", []interface{}{1}},
+    {"operator", map[string]interface{}{"age >=": 18}, "`age` >= ?", []interface{}{18}},
+    {"lower case operator", map[string]interface{}{"name  not  like": "a%"}, "`name` NOT LIKE ?", []interface{}{"a%"}},
+    {"null", map[string]interface{}{"deleted_at": nil}, "`deleted_at` IS NULL", nil},
+    {"not null", map[string]interface{}{"deleted_at !=": nil}, "`deleted_at` IS NOT NULL", nil},
+    {"in", map[string]interface{}{"id": []int{1, 2}}, "`id` IN(?, ?)", []interface{}{1, 2}},
+    {"not in", map[string]interface{}{"id NOT IN": []string{"a"}}, "`id` NOT IN(?)", []interface{}{"a"}},
+    {"empty in", map[string]interface{}{"id": []int{}}, "1 = 0", nil},
+    {"empty not in", map[string]interface{}{"id NOT IN": []int{}}, "1 = 1", nil},
+    {"between", map[string]interface{}{"age BETWEEN": []int{18, 30}}, "`age` BETWEEN ? AND ?", []interface{}{18, 30}},
+    {"binary", map[string]interface{}{"uuid": []byte{1, 2}}, "`uuid` = ?", []interface{}{[]byte{1, 2}}},
+    {"map as json", map[string]interface{}{"meta": map[string]interface{}{"a": 1}}, "`meta` = ?", []interface{}{`{"a":1}`}},
+    {"raw", map[string]interface{}{"total >": mysql.Raw("price * ?", 2)}, "`total` > price * ?", []interface{}{2}},
+    {"qualified column", map[string]interface{}{"u.id": 1}, "`u`.`id` = ?", []interface{}{1}},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      query, values := mysql.BuildSelect("users", test.where)
+      want := "SELECT * FROM `users` WHERE " + test.query + ";"
+      if query != want { t.Errorf("query = %q, want %q", query, want) }
+      if !reflect.DeepEqual(values, test.values) { t.Errorf("values = %#v, want %#v", values, test.values) }
+    })
+  }
+}
+
+func TestBuildSelectOptions(t *testing.T) {
+  tests := []struct {
+    name    string
+    options map[string]interface{}
+    query   string
+  }{
+    {"no options", nil, "SELECT * FROM `users`;"},
+    {"limit", map[string]interface{}{"limit": 10}, "SELECT * FROM `users` LIMIT 0, 10;"},
+    {"offset", map[string]interface{}{"limit": 10, "offset": 20}, "SELECT * FROM `users` LIMIT 20, 10;"},
+    {"order", map[string]interface{}{"order": "id DESC"}, "SELECT * FROM `users` ORDER BY id DESC;"},
+    {"columns", map[string]interface{}{"columns": []string{"id", "name"}}, "SELECT `id`, `name` FROM `users`;"},
+    {"distinct", map[string]interface{}{"distinct": true}, "SELECT DISTINCT * FROM `users`;"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      query, values := mysql.BuildSelect("users", nil, test.options)
+      if query != test.query { t.Errorf("query = %q, want %q", query, test.query) }
+      if len(values) != 0 { t.Errorf("values = %#v, want none", values) }
+    })
+  }
+}
+
+func TestBuildSelectInvalidConditions(t *testing.T) {
+  tests := []struct {
+    name  string
+    where map[string]interface{}
+  }{
+    {"unsupported operator", map[string]interface{}{"id ==": 1}},
+    {"between of one value", map[string]interface{}{"age BETWEEN": []int{18}}},
+    {"between of a scalar", map[string]interface{}{"age BETWEEN": 18}},
+    {"slice of a comparison", map[string]interface{}{"age >": []int{1, 2}}},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      defer func() {
+        if recover() == nil { t.Errorf("BuildSelect(%v) did not panic", test.where) }
+      }()
+      mysql.BuildSelect("users", test.where)
+    })
+  }
+}
+
+func TestBuildUpdate(t *testing.T) {
+  tests := []struct {
+    name    string
+    data    map[string]interface{}
+    where   map[string]interface{}
+    options map[string]interface{}
+    query   string
+    values  []interface{}
+  }{
+    {
+      "value", map[string]interface{}{"name": "bob"}, map[string]interface{}{"id": 1}, nil,
+      "UPDATE `users` SET `name` = ? WHERE `id` = ?;", []interface{}{"bob", 1},
+    },
+    {
+      "null", map[string]interface{}{"deleted_at": nil}, map[string]interface{}{"id": 1}, nil,
+      "UPDATE `users` SET `deleted_at` = NULL WHERE `id` = ?;", []interface{}{1},
+    },
+    {
+      "expression", map[string]interface{}{"visits": mysql.Raw("visits + ?", 1)}, nil, nil,
+      "UPDATE `users` SET `visits` = visits + ?;", []interface{}{1},
+    },
+    {
+      "order and limit", map[string]interface{}{"name": "bob"}, map[string]interface{}{"age >": 18},
+      map[string]interface{}{"order": "id", "limit": 5},
+      "UPDATE `users` SET `name` = ? WHERE `age` > ? ORDER BY id LIMIT 5;", []interface{}{"bob", 18},
+    },
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      query, values := mysql.BuildUpdate("users", test.data, test.where, test.options)
+      if query != test.query { t.Errorf("query = %q, want %q", query, test.query) }
+      if !reflect.DeepEqual(values, test.values) { t.Errorf("values = %#v, want %#v", values, test.values) }
+    })
+  }
+}
+
+func TestBuildDeleteUUID(t *testing.T) {
+  mysql.RegisterModel(mysql.Model{Table: "delete_sessions", UUID: []string{"id"}})
+  mysql.RegisterModel(mysql.Model{Table: "delete_tokens", UUID: []string{"id"}, SoftDelete: "deleted_at"})
+  id     := "0190d9a4-7c3e-7b1a-9f2d-3c4b5a697887"
+  binary := mysql.MustParseUUID(id)
+  want   := []interface{}{binary[:]}
+
+  tests := []struct {
+    name    string
+    table   string
+    options map[string]interface{}
+    query   string
+  }{
+    {"hard delete", "delete_sessions", nil, "DELETE FROM delete_sessions WHERE `id` = ?;"},
+    {"forced delete", "delete_tokens", map[string]interface{}{"force": true}, "DELETE FROM delete_tokens WHERE `id` = ?;"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      query, values := mysql.BuildDelete(test.table, map[string]interface{}{"id": id}, test.options)
+      if query != test.query { t.Errorf("query = %q, want %q", query, test.query) }
+      if !reflect.DeepEqual(values, want) { t.Errorf("values = %#v, want %#v", values, want) }
+    })
+  }
+}
+
+func TestBuildSelectHaving(t *testing.T) {
+  having := mysql.Raw("SUM(total) > ?", 10)
+  sum    := mysql.Raw("SUM(total)")
+  tests := []struct {
+    name    string
+    options map[string]interface{}
+    query   string
+  }{
+    {
+      "with group", map[string]interface{}{"columns": []interface{}{"user_id", sum}, "group": "user_id", "having": having},
+      "SELECT `user_id`, SUM(total) FROM `orders` GROUP BY `user_id` HAVING SUM(total) > ?;",
+    },
+    {
+      "without group", map[string]interface{}{"columns": []interface{}{sum}, "having": having},
+      "SELECT SUM(total) FROM `orders` HAVING SUM(total) > ?;",
+    },
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      query, values := mysql.BuildSelect("orders", nil, test.options)
+      if query != test.query { t.Errorf("query = %q, want %q", query, test.query) }
+      if want := []interface{}{10}; !reflect.DeepEqual(values, want) { t.Errorf("values = %#v, want %#v", values, want) }
+    })
+  }
+}
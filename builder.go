@@ -0,0 +1,185 @@
+package mysql
+
+import "database/sql"
+
+// Key of the where map holding the conditions of a `Query`.
+const builder_where = "\x00where"
+
+// Chainable query builder, for queries which are hard to read as where and 
+// option maps, e.g. with OR conditions. It compiles to the same parameterized 
+// SQL as the map based api, and runs the same hooks. Created by `Table(...)`.
+//
+// Example:
+//   // SELECT * FROM `users` WHERE ((age > ?) AND (status = ? OR vip = 1)) 
+//   //   ORDER BY id DESC LIMIT 0, 10
+//   rows := mysql.Table("users").
+//     Where("age > ?", 18).
+//     Where("status = ? OR vip = 1", "active").
+//     Order("id DESC").
+//     Limit(10).
+//     Select()
+//
+//   // UPDATE `users` SET `status` = ? WHERE ((last_login < ?) OR (banned = 1))
+//   mysql.Table("users").
+//     Where("last_login < ?", cutoff).
+//     OrWhere("banned = 1").
+//     Update(map[string]interface{}{"status": "inactive"})
+type Query struct {
+  handle  *DB
+  table   string
+  where   map[string]interface{}
+  options map[string]interface{}
+}
+
+// Creates a query builder of a table, the name can be followed by an alias 
+// like in `Select(...)`.
+func (d *DB) Table(table string) *Query {
+  return &Query{
+    handle:  d,
+    table:   table,
+    where:   map[string]interface{}{},
+    options: map[string]interface{}{},
+  }
+}
+
+// Adds a condition joined with AND. The condition is raw SQL with `?` 
+// placeholders for `values`, conditions are wrapped in parentheses, so 
+// `Where(a).OrWhere(b).Where(c)` matches `(a) OR (b) AND (c)` with the 
+// precedence of SQL.
+func (q *Query) Where(condition string, values ...interface{}) *Query {
+  return q.add_condition(" AND ", condition, values)
+}
+
+// Adds a condition joined with OR, see `Where(...)`.
+func (q *Query) OrWhere(condition string, values ...interface{}) *Query {
+  return q.add_condition(" OR ", condition, values)
+}
+
+// Adds the conditions of a where map joined with AND, in the format of 
+// `Select(...)`.
+func (q *Query) WhereMap(where map[string]interface{}) *Query {
+  for key, value := range where { q.where[key] = value }
+  return q
+}
+
+// Sets the columns to return, names or expressions, see the option "columns" 
+// of `Select(...)`.
+func (q *Query) Columns(columns ...interface{}) *Query {
+  q.options["columns"] = columns
+  return q
+}
+
+// Returns only distinct rows.
+func (q *Query) Distinct() *Query { return q.Option("distinct", true) }
+
+// Adds tables to join.
+func (q *Query) Join(joins ...Join) *Query {
+  existing, _ := q.options["join"].([]Join)
+  q.options["join"] = append(existing, joins...)
+  return q
+}
+
+// Adds common table expressions to the WITH clause, see `CTE`.
+func (q *Query) With(ctes ...CTE) *Query {
+  existing, _ := q.options["with"].([]CTE)
+  q.options["with"] = append(existing, ctes...)
+  return q
+}
+
+// Adds a named window to the WINDOW clause, used by `Over(...)` columns.
+func (q *Query) Window(name, definition string) *Query {
+  windows, _ := q.options["windows"].(map[string]string)
+  if windows == nil {
+    windows = map[string]string{}
+    q.options["windows"] = windows
+  }
+  windows[name] = definition
+  return q
+}
+
+// Sets the columns of the GROUP BY clause.
+func (q *Query) Group(columns ...string) *Query {
+  return q.Option("group", columns)
+}
+
+// Sets the condition of the HAVING clause.
+func (q *Query) Having(condition string, values ...interface{}) *Query {
+  return q.Option("having", Raw(condition, values...))
+}
+
+// Sets the ORDER BY clause, e.g. "created_at DESC, id".
+func (q *Query) Order(order string) *Query { return q.Option("order", order) }
+
+// Sets the maximum number of rows.
+func (q *Query) Limit(limit int) *Query { return q.Option("limit", limit) }
+
+// Sets the number of rows to skip, used with `Limit(...)`.
+func (q *Query) Offset(offset int) *Query { return q.Option("offset", offset) }
+
+// Locks the selected rows, "update" or "share", see the option "lock" of 
+// `Select(...)`.
+func (q *Query) Lock(mode string) *Query { return q.Option("lock", mode) }
+
+// Sets the "force_index" option, see `Select(...)`.
+func (q *Query) ForceIndex(indexes ...string) *Query { return q.Option("force_index", indexes) }
+
+// Sets the "use_index" option, see `Select(...)`.
+func (q *Query) UseIndex(indexes ...string) *Query { return q.Option("use_index", indexes) }
+
+// Sets the "ignore_index" option, see `Select(...)`.
+func (q *Query) IgnoreIndex(indexes ...string) *Query { return q.Option("ignore_index", indexes) }
+
+// Sets the "hints" option, optimizer hints like "MAX_EXECUTION_TIME(1000)".
+func (q *Query) Hint(hints ...string) *Query { return q.Option("hints", hints) }
+
+// Sets the "tags" option, see `QueryTags`.
+func (q *Query) Tags(tags map[string]string) *Query { return q.Option("tags", tags) }
+
+// Sets any other option of the map based api, e.g. "with_deleted" or "typed".
+func (q *Query) Option(key string, value interface{}) *Query {
+  q.options[key] = value
+  return q
+}
+
+// Runs the query, see `Select(...)`.
+func (q *Query) Select() []map[string]interface{} {
+  return q.handle.Select(q.table, q.where, q.options)
+}
+
+// Runs the query with a limit of 1, see `First(...)`.
+func (q *Query) First() map[string]interface{} {
+  return q.handle.First(q.table, q.where, q.options)
+}
+
+// Runs the query and calls `fn` with every row, see `SelectEach(...)`.
+func (q *Query) Each(fn func(row map[string]interface{}) error) error {
+  return q.handle.SelectEach(q.table, q.where, q.options, fn)
+}
+
+// Returns the number of rows `Select()` returns without its order, limit and 
+// offset, so joins, groups and distinct rows are counted too.
+func (q *Query) Count() (count int64, err error) {
+  defer RecoverError(&err)
+  options := q.handle.select_options(q.options)
+  return q.handle.count_select(q.table, q.where, options), nil
+}
+
+// Updates the matching rows, order and limit apply. See `Update(...)`.
+func (q *Query) Update(data map[string]interface{}) sql.Result {
+  return q.handle.Update(q.table, data, q.where, q.options)
+}
+
+// Deletes the matching rows, order and limit apply. See `Delete(...)`.
+func (q *Query) Delete() sql.Result {
+  return q.handle.Delete(q.table, q.where, q.options)
+}
+
+func (q *Query) add_condition(operator, condition string, values []interface{}) *Query {
+  condition = "(" + condition + ")"
+  if existing, ok := q.where[builder_where].(*Expression); ok {
+    condition = existing.query + operator + condition
+    values    = append(append([]interface{}{}, existing.values...), values...)
+  }
+  q.where[builder_where] = Raw(condition, values...)
+  return q
+}
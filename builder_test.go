@@ -0,0 +1,40 @@
+package mysql_test
+
+import (
+	"reflect"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestQueryFirstKeepsOptions(t *testing.T) {
+  mock  := mysqltest.New(t)
+  query := mysql.Table("orders").Order("id").Limit(10)
+  query.First()
+  query.Select()
+
+  want := []string{
+    "SELECT * FROM `orders` ORDER BY id LIMIT 0, 1;",
+    "SELECT * FROM `orders` ORDER BY id LIMIT 0, 10;",
+  }
+  if got := mock.SQL(); !reflect.DeepEqual(got, want) { t.Fatalf("queries = %q, want %q", got, want) }
+}
+
+func TestQueryCount(t *testing.T) {
+  mock := mysqltest.New(t)
+  mock.On("SELECT COUNT(*)").Rows([]string{"count"}, []interface{}{3})
+
+  count, err := mysql.Table("orders o").
+    Join(mysql.Join{Table: "users u", On: map[string]interface{}{"u.id": mysql.Raw("o.user_id")}}).
+    Group("o.user_id").
+    Limit(2).
+    Count()
+  if err != nil || count != 3 { t.Fatalf("Count = %d, %v", count, err) }
+
+  want := []string{
+    "SELECT COUNT(*) FROM (SELECT 1 FROM `orders` AS `o` INNER JOIN `users` AS `u` " +
+      "ON `u`.`id` = o.user_id GROUP BY `o`.`user_id`) AS `pagination`;",
+  }
+  if got := mock.SQL(); !reflect.DeepEqual(got, want) { t.Fatalf("queries = %q, want %q", got, want) }
+}
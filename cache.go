@@ -0,0 +1,133 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long an expired entry of `CachedFirst(...)` may still be served while 
+// it's refreshed in the background. Older entries are reloaded before 
+// returning.
+var CacheStaleWindow = time.Minute
+
+type cache_entry struct {
+  table      string
+  // Copy of the conditions, for refreshes
+  where      map[string]interface{}
+  row        map[string]interface{}
+  loaded_at  time.Time
+  refreshing bool
+}
+
+var result_cache struct {
+  sync.Mutex
+  entries map[string]*cache_entry
+}
+
+// Same api with `First(...)` method except the row is cached for `ttl`. After 
+// `ttl` the cached row is still returned while it's refreshed in the 
+// background (stale-while-revalidate), so hot lookup tables read on every 
+// request never wait for the database. Rows older than `ttl` plus 
+// `CacheStaleWindow` are reloaded synchronously. Missing rows are cached as 
+// nil as well.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   plan := mysql.CachedFirst("plans", _json{"code": code}, 30*time.Second)
+func CachedFirst(
+  table string,
+  where map[string]interface{},
+  ttl time.Duration,
+) map[string]interface{} {
+  key := cache_key(table, where)
+
+  result_cache.Lock()
+  entry, ok := result_cache.entries[key]
+  if ok {
+    age := time.Since(entry.loaded_at)
+    if age < ttl {
+      defer result_cache.Unlock()
+      return copy_row(entry.row)
+    }
+    if age < ttl+CacheStaleWindow {
+      if !entry.refreshing {
+        entry.refreshing = true
+        go refresh_cache(key, entry)
+      }
+      defer result_cache.Unlock()
+      return copy_row(entry.row)
+    }
+  }
+  result_cache.Unlock()
+
+  where = copy_row(where)
+  row  := First(table, where)
+  store_cache(key, &cache_entry{table: table, where: where, row: row, loaded_at: time.Now()})
+  return copy_row(row)
+}
+
+// Returns the cache key of a row, the query of `First(...)` with its values. 
+// Values of conditions like `Like` or `*Expression` are compared by value, 
+// not by pointer.
+func cache_key(table string, where map[string]interface{}) string {
+  query, values := BuildSelect(table, where, map[string]interface{}{"limit": 1})
+  var key strings.Builder
+  key.WriteString(query)
+  for _, value := range values {
+    if valuer, ok := value.(driver.Valuer); ok {
+      resolved, err := valuer.Value()
+      if err != nil { panic(err) }
+      value = resolved
+    }
+    fmt.Fprintf(&key, "\x00%T:%v", value, value)
+  }
+  return key.String()
+}
+
+// Drops cached rows of a table, or every cached row when `table` is empty. 
+// Call it after writing to a cached table to avoid serving stale rows.
+func InvalidateCache(table string) {
+  result_cache.Lock()
+  defer result_cache.Unlock()
+  for key, entry := range result_cache.entries {
+    if table == "" || entry.table == table {
+      delete(result_cache.entries, key)
+    }
+  }
+}
+
+func refresh_cache(key string, entry *cache_entry) {
+  defer func() {
+    if err := recover(); err != nil {
+      Logger.Printf("mysql: cache refresh of %q failed: %v", entry.table, err)
+      result_cache.Lock()
+      entry.refreshing = false
+      result_cache.Unlock()
+    }
+  }()
+
+  row := First(entry.table, entry.where)
+  store_cache(key, &cache_entry{
+    table: entry.table, where: entry.where, row: row, loaded_at: time.Now(),
+  })
+}
+
+func store_cache(key string, entry *cache_entry) {
+  result_cache.Lock()
+  defer result_cache.Unlock()
+  if result_cache.entries == nil {
+    result_cache.entries = map[string]*cache_entry{}
+  }
+  result_cache.entries[key] = entry
+}
+
+func copy_row(row map[string]interface{}) map[string]interface{} {
+  if row == nil { return nil }
+  result := make(map[string]interface{}, len(row))
+  for column, value := range row { result[column] = value }
+  return result
+}
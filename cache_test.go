@@ -0,0 +1,25 @@
+package mysql_test
+
+import (
+	"testing"
+	"time"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestCachedFirstKey(t *testing.T) {
+  mock := mysqltest.New(t)
+  mysql.InvalidateCache("")
+  defer mysql.InvalidateCache("")
+
+  where := func(value int) map[string]interface{} {
+    return map[string]interface{}{"id": mysql.Raw("? + 0", value)}
+  }
+  mysql.CachedFirst("plans", where(1), time.Minute)
+  mysql.CachedFirst("plans", where(1), time.Minute)
+  if got := len(mock.Queries()); got != 1 { t.Fatalf("queries of equal conditions = %d, want 1", got) }
+
+  mysql.CachedFirst("plans", where(2), time.Minute)
+  if got := len(mock.Queries()); got != 2 { t.Fatalf("queries of other values = %d, want 2", got) }
+}
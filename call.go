@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OUT or INOUT parameter of a stored procedure, created by `Out(...)` or 
+// `InOut(...)`. It's passed as the session variable `@name`.
+type OutParam struct {
+  name  string
+  value interface{}
+  inout bool
+}
+
+// Creates an OUT parameter of `Call(...)`, its value is returned in 
+// `CallResult.Out` under `name`.
+func Out(name string) OutParam {
+  return OutParam{name: name}
+}
+
+// Creates an INOUT parameter of `Call(...)` with an initial value, its value 
+// after the call is returned in `CallResult.Out` under `name`.
+func InOut(name string, value interface{}) OutParam {
+  return OutParam{name: name, value: value, inout: true}
+}
+
+// Results of a stored procedure call.
+type CallResult struct {
+  // Rows of every result set returned by the procedure, in order
+  ResultSets [][]map[string]interface{}
+  // Values of the OUT and INOUT parameters by name, strings like `Select(...)` 
+  // values or nil for NULL
+  Out map[string]interface{}
+}
+
+// Calls a stored procedure with `CALL procedure(?, ?, ...)` and returns its 
+// result sets, and the values of OUT parameters which are read from session 
+// variables on the same connection afterwards.
+//
+// Parameters:
+//   - `procedure`: name of the procedure, optionally qualified by the 
+//                  database like "reports.monthly_sales"
+//   - `args`: IN values, or `Out(...)` and `InOut(...)` parameters
+// Returns:
+//   - *CallResult: result sets and OUT parameter values
+//
+// Example:
+//   // CREATE PROCEDURE order_totals(IN user_id INT, OUT total DECIMAL(10,2))
+//   result := mysql.Call("order_totals", user_id, mysql.Out("total"))
+//   orders := result.ResultSets[0]
+//   total  := result.Out["total"]
+func (d *DB) Call(procedure string, args ...interface{}) *CallResult {
+  var values       []interface{}
+  var placeholders []string
+  var outs         []OutParam
+  for _, arg := range args {
+    out, ok := arg.(OutParam)
+    if !ok {
+      placeholders = append(placeholders, "?")
+      values       = append(values, arg)
+      continue
+    }
+    valid := out.name != ""
+    for i := 0; i < len(out.name); i++ { valid = valid && is_name_char(out.name[i]) }
+    if !valid { panic(fmt.Errorf("mysql: invalid OUT parameter name %q", out.name)) }
+    placeholders = append(placeholders, "@"+out.name)
+    outs         = append(outs, out)
+  }
+
+  query := fmt.Sprintf("CALL %s(%s);", EscapeId(procedure), strings.Join(placeholders, ", "))
+  result := &CallResult{}
+  d.with_conn(func(handle *DB) {
+    for _, out := range outs {
+      if out.inout { handle.Exec("SET @"+out.name+" = ?;", out.value) }
+    }
+
+    result.ResultSets = handle.QueryMulti(query, values...).All()
+    if len(outs) == 0 { return }
+
+    variables := make([]string, len(outs))
+    for i, out := range outs { variables[i] = "@" + out.name + " AS " + EscapeId(out.name) }
+    out_rows := handle.ExecQuery("SELECT " + strings.Join(variables, ", ") + ";")
+    defer out_rows.Close()
+    result.Out = map[string]interface{}{}
+    found := scan_maps(out_rows, map[string]interface{}{"null_as_nil": true})
+    if len(found) > 0 { result.Out = found[0] }
+  })
+  return result
+}
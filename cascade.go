@@ -0,0 +1,94 @@
+package mysql
+
+import "context"
+
+// Number of rows deleted per batch by `DeleteCascade(...)`, unless the option 
+// "batch_size" is given.
+const DefaultCascadeBatchSize = 1000
+
+// Deletes rows of a table matching the `where` conditions together with their 
+// dependent rows, following the `Relations` of registered models recursively. 
+// Dependent rows are deleted before the rows they reference, in batches, all 
+// within one transaction. Use it for schemas which can't use 
+// ON DELETE CASCADE.
+//
+// Parameters:
+//   - `table`: The name of the table
+//   - `where`: The conditions to specify which records to delete
+//   - `options`: Options "batch_size" (int) and "force" (bool), which is 
+//                passed to `Delete(...)` for every table
+// Returns:
+//   - int64: Number of deleted rows over all tables
+//   - error: Error of the transaction
+//
+// Example:
+//   mysql.RegisterModel(mysql.Model{
+//     Table:     "users",
+//     Relations: []mysql.Relation{{Table: "orders", ForeignKey: "user_id"}},
+//   })
+//   mysql.RegisterModel(mysql.Model{
+//     Table:     "orders",
+//     Relations: []mysql.Relation{{Table: "order_items", ForeignKey: "order_id"}},
+//   })
+//
+//   // deletes order_items, then orders, then the user
+//   deleted, err := mysql.DeleteCascade("users", _json{"id": 42})
+func (d *DB) DeleteCascade(
+  table string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) (deleted int64, err error) {
+  options := map[string]interface{}{}
+  if len(args) > 0 && args[0] != nil { options = args[0] }
+  batch_size, ok := options["batch_size"].(int)
+  if !ok { batch_size = DefaultCascadeBatchSize }
+
+  defer RecoverError(&err)
+  err = d.Transaction(d.context(), func(ctx context.Context) error {
+    deleted, err = TxFromContext(ctx).delete_cascade(table, where, batch_size, options)
+    return err
+  })
+  return deleted, err
+}
+
+func (d *DB) delete_cascade(
+  table string,
+  where map[string]interface{},
+  batch_size int,
+  options map[string]interface{},
+) (int64, error) {
+  model := ModelOf(table)
+  if model == nil || len(model.Relations) == 0 {
+    n, _ := d.Delete(table, where, options).RowsAffected()
+    return n, nil
+  }
+
+  var deleted int64
+  key := model.PrimaryKey
+  err := d.SelectInBatches(table, where, batch_size,
+    func(rows []map[string]interface{}) error {
+      for _, relation := range model.Relations {
+        local_key := relation.LocalKey
+        if local_key == "" { local_key = key }
+
+        values := make([]interface{}, 0, len(rows))
+        for _, row := range rows {
+          if row[local_key] != nil { values = append(values, row[local_key]) }
+        }
+        if len(values) == 0 { continue }
+
+        where := map[string]interface{}{relation.ForeignKey: values}
+        n, err := d.delete_cascade(relation.Table, where, batch_size, options)
+        if err != nil { return err }
+        deleted += n
+      }
+
+      ids := make([]interface{}, len(rows))
+      for i, row := range rows { ids[i] = row[key] }
+      n, _ := d.Delete(table, map[string]interface{}{key: ids}, options).RowsAffected()
+      deleted += n
+      return nil
+    },
+  )
+  return deleted, err
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+var collation_name = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Columns already reported by the `IgnoreCase(...)` index warning.
+var ignore_case_warnings sync.Map
+
+type collate struct {
+  value     interface{}
+  collation string
+}
+
+type ignore_case struct {
+  value interface{}
+}
+
+// Wraps a where map value, so the comparison uses the given collation instead 
+// of the column's one. Useful for mixed-collation schemas, e.g. comparing a 
+// case-sensitive column case-insensitively.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // SELECT * FROM `users` WHERE `email` = ? COLLATE utf8mb4_general_ci
+//   mysql.First("users", _json{"email": mysql.Collate(email, "utf8mb4_general_ci")})
+func Collate(value interface{}, collation string) interface{} {
+  if !collation_name.MatchString(collation) {
+    panic(fmt.Errorf("mysql: invalid collation name %q", collation))
+  }
+  return collate{value, collation}
+}
+
+// Wraps a where map value, so the comparison is case-insensitive regardless of 
+// the column's collation by comparing `LOWER(column)` with `LOWER(value)`.
+//
+// Wrapping the column in a function prevents MySQL from using an index on it. 
+// Prefer `Collate(...)` or a case-insensitive column collation for large 
+// tables, in `Debug` mode a warning is logged once per column.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // SELECT * FROM `tags` WHERE LOWER(`name`) LIKE LOWER(?)
+//   mysql.Select("tags", _json{"name LIKE": mysql.IgnoreCase("go%")})
+func IgnoreCase(value interface{}) interface{} {
+  return ignore_case{value}
+}
+
+func (c collate) condition(column, operator string) (string, []interface{}) {
+  operator = scalar_operator(operator)
+  query := fmt.Sprintf("%s %s ? COLLATE %s", column, operator, c.collation)
+  return query, []interface{}{c.value}
+}
+
+func (c ignore_case) condition(column, operator string) (string, []interface{}) {
+  if Debug {
+    if _, warned := ignore_case_warnings.LoadOrStore(column, true); !warned {
+      Logger.Printf("mysql: LOWER(%s) can't use an index on the column", column)
+    }
+  }
+  operator = scalar_operator(operator)
+  query := fmt.Sprintf("LOWER(%s) %s LOWER(?)", column, operator)
+  return query, []interface{}{c.value}
+}
+
+func scalar_operator(operator string) string {
+  switch operator {
+  case "", "IN": return "="
+  case "NOT IN": return "!="
+  }
+  return operator
+}
@@ -0,0 +1,240 @@
+package mysql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	m "github.com/go-sql-driver/mysql"
+)
+
+// TLS settings of `Config`, needed for managed MySQL services like RDS or 
+// Cloud SQL which require encrypted connections.
+//
+// Example config.yml:
+//   database:
+//     host: mydb.abc123.eu-west-1.rds.amazonaws.com
+//     tls:
+//       ca: /etc/ssl/rds-global-bundle.pem
+type TLSConfig struct {
+  // Path of the PEM encoded CA certificates which verify the server, the 
+  // system roots are used when empty
+  CA string `yaml:"ca,omitempty"`
+  // Paths of the PEM encoded client certificate and key, for servers 
+  // requiring client authentication
+  Cert string `yaml:"cert,omitempty"`
+  Key  string `yaml:"key,omitempty"`
+  // Expected server name in the certificate, `Host` when empty
+  ServerName string `yaml:"server_name,omitempty"`
+  // Skip verification of the server certificate. Only for development, the 
+  // connection is encrypted but not protected against impersonation.
+  SkipVerify bool `yaml:"skip_verify,omitempty"`
+}
+
+// Name of the environment variable which selects the active profile. It takes 
+// precedence over `Config.Profile` when the profile is defined in 
+// `Config.Profiles`.
+var ProfileEnv = "APP_ENV"
+
+// Returns the configuration of the active profile. The profile name is read 
+// from the `ProfileEnv` environment variable, or `cfg.Profile` when the 
+// variable is not set or names no profile of `cfg.Profiles`, so deployments 
+// setting `APP_ENV=production` without profiles use the base configuration. 
+// Without a profile name `cfg` itself is returned.
+//
+// It panics when `cfg.Profile` is not defined, so a mistyped profile never 
+// silently connects to the default database.
+//
+// Example config.yml:
+//   database:
+//     host: 127.0.0.1
+//     name: my_app
+//     user: jeefo
+//     profiles:
+//       test:
+//         name: my_app_test
+//       production:
+//         host: db.internal
+//         pass: secret
+//
+// Then `APP_ENV=test ./my_app` connects to `my_app_test` on 127.0.0.1.
+func (cfg *Config) Active() *Config {
+  if cfg.resolved { return cfg }
+  if name := os.Getenv(ProfileEnv); name != "" {
+    if _, ok := cfg.Profiles[name]; ok { return cfg.WithProfile(name) }
+  }
+  if cfg.Profile == "" { return cfg }
+  return cfg.WithProfile(cfg.Profile)
+}
+
+// Returns a copy of `cfg` with the non-zero fields of profile `name` applied 
+// on top of it. Zero values in a profile (empty strings, 0, false) can't 
+// override the base configuration. An empty profile, e.g. `production:` 
+// without settings in YAML, keeps the base configuration.
+func (cfg *Config) WithProfile(name string) *Config {
+  profile, ok := cfg.Profiles[name]
+  if !ok {
+    panic(fmt.Errorf("mysql: config profile %q is not defined", name))
+  }
+
+  result := *cfg
+  if profile != nil {
+    base   := reflect.ValueOf(&result).Elem()
+    values := reflect.ValueOf(profile).Elem()
+    for i := 0; i < values.NumField(); i++ {
+      if field := values.Field(i); field.CanInterface() && !field.IsZero() {
+        base.Field(i).Set(field)
+      }
+    }
+  }
+
+  result.Profile  = name
+  result.Profiles = nil
+  result.resolved = true
+  return &result
+}
+
+// Builds the driver configuration of a connection pool.
+func driver_config(cfg *Config) *m.Config {
+  dc := m.NewConfig()
+  dc.User   = cfg.Username
+  dc.Passwd = cfg.Password
+  dc.DBName = cfg.DBName
+
+  socket := cfg.Socket
+  if socket == "" && cfg.AutoSocket && cfg.Host == "localhost" && cfg.SSH == nil {
+    socket = detect_socket()
+  }
+  if socket != "" {
+    dc.Net  = "unix"
+    dc.Addr = socket
+  } else {
+    dc.Net  = "tcp"
+    dc.Addr = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+  }
+  if cfg.SSH != nil { dc.Net = ssh_network(cfg.SSH, dc.Net) }
+
+  dc.ParseTime    = cfg.ParseTime
+  dc.Timeout      = cfg.Timeout
+  dc.ReadTimeout  = cfg.ReadTimeout
+  dc.WriteTimeout = cfg.WriteTimeout
+  dc.ClientFoundRows = cfg.ClientFoundRows
+  // The handshake collation sets the utf8mb4 character set, any other one 
+  // is set by the driver with `SET NAMES` after connecting.
+  switch cfg.Charset {
+  case "", "utf8mb4":
+    dc.Collation = "utf8mb4_unicode_ci"
+  default:
+    dc.Params = map[string]string{"charset": cfg.Charset}
+  }
+  if cfg.Collation != "" { dc.Collation = cfg.Collation }
+  if cfg.Location != "" {
+    location, err := time.LoadLocation(cfg.Location)
+    if err != nil { panic(err) }
+    dc.Loc = location
+  }
+
+  if len(cfg.Params) > 0 {
+    // Let the driver parse extra parameters, so known ones like 
+    // `interpolateParams` aren't mistaken for session variables.
+    params := url.Values{}
+    for key, value := range cfg.Params { params.Set(key, value) }
+    dsn := dc.FormatDSN()
+    separator := "?"
+    if strings.Contains(dsn, "?") { separator = "&" }
+
+    parsed, err := m.ParseDSN(dsn + separator + params.Encode())
+    if err != nil { panic(err) }
+    dc = parsed
+  }
+
+  if cfg.Compress { dc.Apply(m.EnableCompression(true)) }
+  switch {
+  case cfg.MaxAllowedPacket > 0:
+    dc.MaxAllowedPacket = cfg.MaxAllowedPacket
+  case cfg.MaxAllowedPacket < 0:
+    dc.MaxAllowedPacket = 0
+  }
+  if cfg.InterpolateParams { dc.InterpolateParams = true }
+  if cfg.MultiStatements   { dc.MultiStatements = true }
+
+  dc.ConnectionAttributes = connection_attributes(cfg)
+
+  if cfg.Analytic {
+    dc.InterpolateParams = true
+    if dc.Params == nil { dc.Params = map[string]string{} }
+    for _, name := range []string{"net_read_timeout", "net_write_timeout"} {
+      if _, ok := dc.Params[name]; !ok { dc.Params[name] = "3600" }
+    }
+  }
+
+  if cfg.TLS != nil {
+    tls_config, err := cfg.TLS.build(cfg.Host)
+    if err != nil { panic(err) }
+    dc.TLS = tls_config
+  }
+  if cfg.Credentials != nil {
+    // Auth tokens are sent in cleartext, only over TLS or a Unix socket
+    dc.AllowCleartextPasswords = dc.TLS != nil || dc.Net == "unix"
+    cache := &credential_cache{provider: cfg.Credentials}
+    dc.Apply(m.BeforeConnect(cache.before_connect))
+  }
+  return dc
+}
+
+// Encodes the connection attributes like "key:value,key:value". Separators 
+// can't be escaped, so they are replaced in keys and values.
+func connection_attributes(cfg *Config) string {
+  attributes := map[string]string{"program_name": cfg.ProgramName}
+  if attributes["program_name"] == "" {
+    attributes["program_name"] = filepath.Base(os.Args[0])
+  }
+  if host, err := os.Hostname(); err == nil { attributes["host"] = host }
+  for key, value := range cfg.ConnectionAttributes { attributes[key] = value }
+
+  keys := make([]string, 0, len(attributes))
+  for key := range attributes { keys = append(keys, key) }
+  sort.Strings(keys)
+
+  pairs := make([]string, len(keys))
+  for i, key := range keys {
+    pairs[i] = strings.NewReplacer(",", "_", ":", "_").Replace(key) + ":" +
+      strings.ReplaceAll(attributes[key], ",", "_")
+  }
+  return strings.Join(pairs, ",")
+}
+
+func (t *TLSConfig) build(host string) (*tls.Config, error) {
+  config := &tls.Config{
+    ServerName:         t.ServerName,
+    InsecureSkipVerify: t.SkipVerify,
+  }
+  if config.ServerName == "" { config.ServerName = host }
+
+  if t.CA != "" {
+    pem, err := os.ReadFile(t.CA)
+    if err != nil { return nil, err }
+    config.RootCAs = x509.NewCertPool()
+    if !config.RootCAs.AppendCertsFromPEM(pem) {
+      return nil, fmt.Errorf("mysql: no certificates found in %q", t.CA)
+    }
+  }
+
+  if t.Cert != "" || t.Key != "" {
+    if t.Cert == "" || t.Key == "" {
+      return nil, errors.New("mysql: TLS client certificate needs both cert and key")
+    }
+    certificate, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+    if err != nil { return nil, err }
+    config.Certificates = []tls.Certificate{certificate}
+  }
+  return config, nil
+}
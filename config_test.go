@@ -0,0 +1,48 @@
+package mysql_test
+
+import (
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+func TestConfigActive(t *testing.T) {
+  profiles := map[string]*mysql.Config{"test": {DBName: "app_test"}, "empty": nil}
+  tests := []struct {
+    name     string
+    env      string
+    profile  string
+    profiles map[string]*mysql.Config
+    db_name  string
+  }{
+    {"no profile", "", "", profiles, "app"},
+    {"env without profiles", "production", "", nil, "app"},
+    {"env of an undefined profile", "production", "", profiles, "app"},
+    {"env profile", "test", "", profiles, "app_test"},
+    {"env over explicit profile", "test", "empty", profiles, "app_test"},
+    {"explicit profile", "", "test", profiles, "app_test"},
+    {"nil profile", "", "empty", profiles, "app"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      t.Setenv(mysql.ProfileEnv, test.env)
+      cfg := &mysql.Config{DBName: "app", Profile: test.profile, Profiles: test.profiles}
+      active := cfg.Active()
+      if active.DBName != test.db_name {
+        t.Fatalf("DBName = %q, want %q", active.DBName, test.db_name)
+      }
+      if again := active.Active(); again.DBName != test.db_name {
+        t.Fatalf("DBName of the active config = %q, want %q", again.DBName, test.db_name)
+      }
+    })
+  }
+}
+
+func TestConfigActiveUndefinedProfile(t *testing.T) {
+  t.Setenv(mysql.ProfileEnv, "")
+  defer func() {
+    if recover() == nil { t.Fatal("expected a panic for an undefined explicit profile") }
+  }()
+  (&mysql.Config{Profile: "staging"}).Active()
+}
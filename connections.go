@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var connections struct {
+  sync.RWMutex
+  handles map[string]*DB
+}
+
+// Opens a named connection pool besides the default one of `Init(...)`, e.g. 
+// to a reporting replica with `Config.Analytic` enabled, and returns its 
+// handle. Connecting a name again swaps the pool of its handle like 
+// `Reload(...)`, keeping its `Config.Analytic` setting.
+//
+// Example:
+//   mysql.Connect("reports", &mysql.Config{
+//     Host: "replica.internal", Port: 3306, DBName: "shop", Username: "reports",
+//     Analytic: true,
+//   })
+//
+//   err := mysql.Use("reports").SelectEach("orders", where, nil, write_row)
+func Connect(name string, cfg *Config) *DB {
+  cfg  = cfg.Active()
+  pool := open(cfg)
+
+  connections.Lock()
+  defer connections.Unlock()
+  if connections.handles == nil { connections.handles = map[string]*DB{} }
+
+  if handle, ok := connections.handles[name]; ok {
+    if old := handle.pool.Swap(pool); old != nil {
+      if err := old.Close(); err != nil { Logger.Println(err) }
+    }
+    reset_schema_cache()
+    return handle
+  }
+
+  handle := &DB{pool: &atomic.Pointer[sql.DB]{}, analytic: cfg.Analytic}
+  handle.pool.Store(pool)
+  connections.handles[name] = handle
+  return handle
+}
+
+// Returns a handle of an existing connection pool, which isn't registered by 
+// name.
+func NewDB(pool *sql.DB) *DB {
+  handle := &DB{pool: &atomic.Pointer[sql.DB]{}}
+  handle.pool.Store(pool)
+  return handle
+}
+
+// Returns the handle of a connection opened by `Connect(...)`. It panics when 
+// the name isn't connected.
+func Use(name string) *DB {
+  connections.RLock()
+  handle, ok := connections.handles[name]
+  connections.RUnlock()
+  if !ok { panic(fmt.Errorf("mysql: connection %q is not connected", name)) }
+  return handle
+}
+
+func close_connections() error {
+  connections.Lock()
+  defer connections.Unlock()
+
+  var err error
+  for name, handle := range connections.handles {
+    if close_err := handle.pool.Load().Close(); close_err != nil { err = close_err }
+    delete(connections.handles, name)
+  }
+  return err
+}
@@ -0,0 +1,142 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var converters struct {
+  sync.RWMutex
+  decoders map[string]func([]byte) (interface{}, error)
+  encoders map[reflect.Type]func(interface{}) (interface{}, error)
+}
+
+var error_type = reflect.TypeOf((*error)(nil)).Elem()
+
+// Registers a converter of a column type or of a Go type, replacing the 
+// previous one, nil removes it.
+//
+// A column type like "DECIMAL", "SET" or "GEOMETRY" (POINT and other spatial 
+// types), as named by `sql.ColumnType.DatabaseTypeName()`, takes a 
+// `func([]byte) (interface{}, error)` decoding the values of result maps. It 
+// takes precedence over typed values, see `TypedValues`, and isn't called for 
+// NULL.
+//
+// Any other target is a value of the Go type, or its `reflect.Type`, and takes 
+// a `func(T) (V, error)` encoding values of the type into values of the 
+// driver. Encoders apply to the values of every query, like the data and 
+// where maps of `Insert(...)` and `Update(...)`, except slices of where maps 
+// which are expanded into IN lists.
+//
+// Example:
+//   mysql.RegisterConverter("DECIMAL", func(value []byte) (interface{}, error) {
+//     rat, ok := new(big.Rat).SetString(string(value))
+//     if !ok { return nil, fmt.Errorf("invalid decimal %q", value) }
+//     return rat, nil
+//   })
+//   mysql.RegisterConverter((*big.Rat)(nil), func(value *big.Rat) (string, error) {
+//     return value.FloatString(10), nil
+//   })
+//
+//   mysql.RegisterConverter("SET", func(value []byte) (interface{}, error) {
+//     if len(value) == 0 { return []string{}, nil }
+//     return strings.Split(string(value), ","), nil
+//   })
+func RegisterConverter(target interface{}, fn interface{}) {
+  converters.Lock()
+  defer converters.Unlock()
+
+  if column_type, ok := target.(string); ok {
+    column_type = strings.ToUpper(column_type)
+    if converters.decoders == nil {
+      converters.decoders = map[string]func([]byte) (interface{}, error){}
+    }
+    if fn == nil {
+      delete(converters.decoders, column_type)
+      return
+    }
+    decoder, ok := fn.(func([]byte) (interface{}, error))
+    if !ok {
+      panic(fmt.Errorf("mysql: converter of %s must be a func([]byte) (interface{}, error), not %T", column_type, fn))
+    }
+    converters.decoders[column_type] = decoder
+    return
+  }
+
+  go_type, ok := target.(reflect.Type)
+  if !ok { go_type = reflect.TypeOf(target) }
+  if go_type == nil { panic(fmt.Errorf("mysql: converter target must not be nil")) }
+  if converters.encoders == nil {
+    converters.encoders = map[reflect.Type]func(interface{}) (interface{}, error){}
+  }
+  if fn == nil {
+    delete(converters.encoders, go_type)
+    return
+  }
+  converters.encoders[go_type] = encoder_of(go_type, fn)
+}
+
+// Wraps a `func(T) (V, error)` encoding values of `go_type`.
+func encoder_of(go_type reflect.Type, fn interface{}) func(interface{}) (interface{}, error) {
+  f := reflect.ValueOf(fn)
+  kind := f.Type()
+  if kind.Kind() != reflect.Func || kind.NumIn() != 1 || !go_type.AssignableTo(kind.In(0)) ||
+    kind.NumOut() != 2 || kind.Out(1) != error_type {
+    panic(fmt.Errorf("mysql: converter of %s must be a func(%s) (V, error), not %T", go_type, go_type, fn))
+  }
+  return func(value interface{}) (interface{}, error) {
+    results := f.Call([]reflect.Value{reflect.ValueOf(value)})
+    err, _ := results[1].Interface().(error)
+    return results[0].Interface(), err
+  }
+}
+
+// Returns the decoders of column types, or nil when there is none.
+func column_decoders() map[string]func([]byte) (interface{}, error) {
+  converters.RLock()
+  defer converters.RUnlock()
+  if len(converters.decoders) == 0 { return nil }
+  decoders := make(map[string]func([]byte) (interface{}, error), len(converters.decoders))
+  for column_type, decoder := range converters.decoders { decoders[column_type] = decoder }
+  return decoders
+}
+
+// Returns the converter of result maps calling a decoder with the bytes of 
+// non NULL values.
+func decoded(decoder func([]byte) (interface{}, error)) func(interface{}) interface{} {
+  return func(value interface{}) interface{} {
+    var bytes []byte
+    switch value := value.(type) {
+    case nil:       return nil
+    case []byte:    bytes = value
+    case time.Time: bytes = []byte(value.Format("2006-01-02 15:04:05.999999"))
+    default:        bytes = []byte(fmt.Sprint(typed_string(value)))
+    }
+    decoded, err := decoder(bytes)
+    if err != nil { panic(err) }
+    return decoded
+  }
+}
+
+// Returns the values of a query with the values of registered Go types 
+// encoded. `values` is returned as is when nothing is encoded.
+func encode_values(values []interface{}) ([]interface{}, error) {
+  converters.RLock()
+  defer converters.RUnlock()
+  if len(converters.encoders) == 0 { return values, nil }
+
+  var encoded []interface{}
+  for i, value := range values {
+    encoder := converters.encoders[reflect.TypeOf(value)]
+    if encoder == nil { continue }
+    if encoded == nil { encoded = append([]interface{}(nil), values...) }
+    result, err := encoder(value)
+    if err != nil { return nil, fmt.Errorf("mysql: encode %T: %w", value, err) }
+    encoded[i] = result
+  }
+  if encoded == nil { return values, nil }
+  return encoded, nil
+}
@@ -0,0 +1,48 @@
+package mysql
+
+import "database/sql"
+
+// Atomically adds `amount` to a numeric column of the rows matching `where`, 
+// with `UPDATE t SET column = column + ?`, so counters don't need a racy read 
+// and write. Options are the options of `Update(...)`.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // UPDATE `posts` SET `views` = `views` + ? WHERE `id` = ?
+//   mysql.Increment("posts", "views", 1, _json{"id": id})
+func (d *DB) Increment(
+  table, column string,
+  amount interface{},
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  data := map[string]interface{}{
+    column: Raw(EscapeId(column)+" + ?", amount),
+  }
+  return d.Update(table, data, where, options...)
+}
+
+// Same api with `Increment(...)` method except it subtracts `amount`.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // UPDATE `stocks` SET `amount` = `amount` - ? WHERE `product_id` = ? 
+//   //   AND `amount` >= ?
+//   result := mysql.Decrement("stocks", "amount", 2, _json{
+//     "product_id": product_id,
+//     "amount >=":  2,
+//   })
+//   if n, _ := result.RowsAffected(); n == 0 { return ErrOutOfStock }
+func (d *DB) Decrement(
+  table, column string,
+  amount interface{},
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  data := map[string]interface{}{
+    column: Raw(EscapeId(column)+" - ?", amount),
+  }
+  return d.Update(table, data, where, options...)
+}
@@ -0,0 +1,241 @@
+package mysql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	m "github.com/go-sql-driver/mysql"
+)
+
+// Source of the password of new connections, used instead of 
+// `Config.Password` when `Config.Credentials` is set, e.g. for the short 
+// lived IAM auth tokens of AWS RDS or Cloud SQL. Passwords are cached until 
+// one minute before they expire, a zero expiry is never cached.
+type CredentialProvider interface {
+  // Returns the password of `user` connecting to `address` ("host:port"), 
+  // and when it expires
+  Password(ctx context.Context, address, user string) (string, time.Time, error)
+}
+
+// Function implementing `CredentialProvider`, e.g. for a secret manager.
+//
+// Example:
+//   cfg.Credentials = mysql.CredentialFunc(func(ctx context.Context, address, user string) (string, time.Time, error) {
+//     secret, err := vault.Read(ctx, "database/creds/"+user)
+//     if err != nil { return "", time.Time{}, err }
+//     return secret.Password, time.Now().Add(secret.LeaseDuration), nil
+//   })
+type CredentialFunc func(ctx context.Context, address, user string) (string, time.Time, error)
+
+func (f CredentialFunc) Password(ctx context.Context, address, user string) (string, time.Time, error) {
+  return f(ctx, address, user)
+}
+
+// Password of a connection pool, refreshed by `CredentialProvider`.
+type credential_cache struct {
+  provider CredentialProvider
+  mu       sync.Mutex
+  key      string
+  password string
+  expires  time.Time
+}
+
+// Sets the password of the driver configuration before every connection.
+func (c *credential_cache) before_connect(ctx context.Context, dc *m.Config) error {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  key := dc.Addr + "\x00" + dc.User
+  if c.key != key || c.expires.IsZero() || time.Until(c.expires) < time.Minute {
+    password, expires, err := c.provider.Password(ctx, dc.Addr, dc.User)
+    if err != nil { return fmt.Errorf("mysql: credentials: %w", err) }
+    c.key, c.password, c.expires = key, password, expires
+  }
+  dc.Passwd = c.password
+  return nil
+}
+
+// AWS credentials signing the auth tokens of `RDSIAMAuth`.
+type AWSCredentials struct {
+  AccessKeyID     string
+  SecretAccessKey string
+  SessionToken    string
+}
+
+// `CredentialProvider` of the IAM database authentication of AWS RDS and 
+// Aurora. Passwords are auth tokens valid for 15 minutes, signed with AWS 
+// credentials. IAM authentication needs `Config.TLS`, the tokens are sent in 
+// cleartext.
+//
+// Example:
+//   cfg.TLS = &mysql.TLSConfig{CA: "/etc/ssl/rds-global-bundle.pem"}
+//   cfg.Credentials = &mysql.RDSIAMAuth{Region: "eu-west-1"}
+//
+//   // with the credentials chain of aws-sdk-go-v2
+//   aws_cfg, _ := config.LoadDefaultConfig(ctx)
+//   cfg.Credentials = &mysql.RDSIAMAuth{
+//     Region: aws_cfg.Region,
+//     AWSCredentials: func(ctx context.Context) (mysql.AWSCredentials, error) {
+//       creds, err := aws_cfg.Credentials.Retrieve(ctx)
+//       return mysql.AWSCredentials{creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken}, err
+//     },
+//   }
+type RDSIAMAuth struct {
+  // Region of the database, AWS_REGION or AWS_DEFAULT_REGION when empty
+  Region string
+  // Source of the signing credentials, AWS_ACCESS_KEY_ID, 
+  // AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN when nil
+  AWSCredentials func(ctx context.Context) (AWSCredentials, error)
+}
+
+// Lifetime of the auth tokens of RDS.
+const rds_token_lifetime = 15 * time.Minute
+
+func (r *RDSIAMAuth) Password(ctx context.Context, address, user string) (string, time.Time, error) {
+  region := r.Region
+  if region == "" { region = os.Getenv("AWS_REGION") }
+  if region == "" { region = os.Getenv("AWS_DEFAULT_REGION") }
+  if region == "" { return "", time.Time{}, errors.New("RDS region is not set") }
+
+  var creds AWSCredentials
+  if r.AWSCredentials != nil {
+    var err error
+    if creds, err = r.AWSCredentials(ctx); err != nil { return "", time.Time{}, err }
+  } else {
+    creds = AWSCredentials{
+      os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"),
+      os.Getenv("AWS_SESSION_TOKEN"),
+    }
+  }
+  if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+    return "", time.Time{}, errors.New("AWS credentials are not set")
+  }
+
+  now := time.Now().UTC()
+  return rds_auth_token(address, region, user, creds, now), now.Add(rds_token_lifetime), nil
+}
+
+// Returns the auth token of RDS, an URL presigned with AWS Signature 
+// Version 4 without its scheme.
+func rds_auth_token(address, region, user string, creds AWSCredentials, now time.Time) string {
+  date  := now.Format("20060102")
+  stamp := now.Format("20060102T150405Z")
+  scope := date + "/" + region + "/rds-db/aws4_request"
+
+  params := map[string]string{
+    "Action":              "connect",
+    "DBUser":              user,
+    "X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+    "X-Amz-Credential":    creds.AccessKeyID + "/" + scope,
+    "X-Amz-Date":          stamp,
+    "X-Amz-Expires":       fmt.Sprint(int(rds_token_lifetime.Seconds())),
+    "X-Amz-SignedHeaders": "host",
+  }
+  if creds.SessionToken != "" { params["X-Amz-Security-Token"] = creds.SessionToken }
+  keys := make([]string, 0, len(params))
+  for key := range params { keys = append(keys, key) }
+  sort.Strings(keys)
+  pairs := make([]string, len(keys))
+  for i, key := range keys { pairs[i] = aws_escape(key) + "=" + aws_escape(params[key]) }
+  query := strings.Join(pairs, "&")
+
+  empty_payload := sha256.Sum256(nil)
+  canonical := strings.Join([]string{
+    "GET", "/", query, "host:" + address, "", "host", hex.EncodeToString(empty_payload[:]),
+  }, "\n")
+  canonical_hash := sha256.Sum256([]byte(canonical))
+  to_sign := strings.Join([]string{
+    "AWS4-HMAC-SHA256", stamp, scope, hex.EncodeToString(canonical_hash[:]),
+  }, "\n")
+
+  key := []byte("AWS4" + creds.SecretAccessKey)
+  for _, part := range []string{date, region, "rds-db", "aws4_request"} {
+    key = hmac_sha256(key, part)
+  }
+  signature := hex.EncodeToString(hmac_sha256(key, to_sign))
+  return address + "/?" + query + "&X-Amz-Signature=" + signature
+}
+
+func hmac_sha256(key []byte, data string) []byte {
+  mac := hmac.New(sha256.New, key)
+  mac.Write([]byte(data))
+  return mac.Sum(nil)
+}
+
+// URI encoding of AWS Signature Version 4, every byte except unreserved 
+// characters.
+func aws_escape(value string) string {
+  return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// `CredentialProvider` of the IAM database authentication of Cloud SQL. 
+// Passwords are OAuth2 access tokens of the service account, the user is its 
+// email without ".gserviceaccount.com". IAM authentication needs 
+// `Config.TLS`, the tokens are sent in cleartext. The Cloud SQL Auth Proxy 
+// with `--auto-iam-authn` is an alternative which needs no password.
+//
+// Example:
+//   cfg.Username    = "orders@my-project.iam"
+//   cfg.Credentials = &mysql.CloudSQLIAMAuth{}
+//
+//   // with golang.org/x/oauth2/google
+//   source, _ := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/sqlservice.login")
+//   cfg.Credentials = &mysql.CloudSQLIAMAuth{
+//     Token: func(ctx context.Context) (string, time.Time, error) {
+//       token, err := source.Token()
+//       if err != nil { return "", time.Time{}, err }
+//       return token.AccessToken, token.Expiry, nil
+//     },
+//   }
+type CloudSQLIAMAuth struct {
+  // Source of the access tokens, the metadata server of Compute Engine, GKE 
+  // or Cloud Run when nil
+  Token func(ctx context.Context) (string, time.Time, error)
+}
+
+func (c *CloudSQLIAMAuth) Password(ctx context.Context, address, user string) (string, time.Time, error) {
+  if c.Token != nil { return c.Token(ctx) }
+  return metadata_token(ctx)
+}
+
+// Returns the access token of the default service account from the metadata 
+// server, or of `GCE_METADATA_HOST`.
+func metadata_token(ctx context.Context) (string, time.Time, error) {
+  host := os.Getenv("GCE_METADATA_HOST")
+  if host == "" { host = "metadata.google.internal" }
+  endpoint := "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token"
+
+  request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+  if err != nil { return "", time.Time{}, err }
+  request.Header.Set("Metadata-Flavor", "Google")
+  client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{
+    DialContext: (&net.Dialer{Timeout: 2 * time.Second}).DialContext,
+  }}
+  response, err := client.Do(request)
+  if err != nil { return "", time.Time{}, err }
+  defer response.Body.Close()
+  if response.StatusCode != http.StatusOK {
+    return "", time.Time{}, fmt.Errorf("metadata server: %s", response.Status)
+  }
+
+  var token struct {
+    AccessToken string `json:"access_token"`
+    ExpiresIn   int64  `json:"expires_in"`
+  }
+  if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+    return "", time.Time{}, err
+  }
+  return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn) * time.Second), nil
+}
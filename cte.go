@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Common table expression for the `"with"` option of `Select(...)`, MySQL 8 
+// and later. The CTE can be selected by its name, joined, or used in 
+// subqueries of where maps.
+type CTE struct {
+  // Name the query refers to the CTE by
+  Name string
+  // Optional column names of the CTE, the columns of `Query` when empty
+  Columns []string
+  // SELECT statement of the CTE, created by `Raw(...)` or `Subquery(...)`
+  Query *Expression
+  // Lets the query refer to the CTE itself, e.g. to walk a tree. It makes 
+  // the whole WITH clause recursive, like in SQL.
+  Recursive bool
+}
+
+// Returns the WITH clause of the "with" option, followed by a space.
+func with_query(options map[string]interface{}) (string, []interface{}) {
+  var ctes []CTE
+  switch value := options["with"].(type) {
+  case CTE:   ctes = []CTE{value}
+  case []CTE: ctes = value
+  case nil:   return "", nil
+  default:
+    panic(fmt.Errorf("mysql: invalid with option type %T", value))
+  }
+
+  var values []interface{}
+  recursive  := false
+  clauses    := make([]string, len(ctes))
+  for i, cte := range ctes {
+    if cte.Name == "" || cte.Query == nil {
+      panic(fmt.Errorf("mysql: CTE %d needs a name and a query", i))
+    }
+    clause := EscapeId(cte.Name, true)
+    if len(cte.Columns) > 0 {
+      columns := make([]string, len(cte.Columns))
+      for j, column := range cte.Columns { columns[j] = EscapeId(column, true) }
+      clause += " (" + strings.Join(columns, ", ") + ")"
+    }
+    query := cte.Query.query
+    if !cte.Query.subquery { query = "(" + query + ")" }
+    clauses[i] = clause + " AS " + query
+    values     = append(values, cte.Query.values...)
+    recursive  = recursive || cte.Recursive
+  }
+
+  with := "WITH "
+  if recursive { with = "WITH RECURSIVE " }
+  return with + strings.Join(clauses, ", ") + " ", values
+}
+
+// Creates a window function column like 
+// `ROW_NUMBER() OVER (PARTITION BY ...) AS alias` for the "columns" option of 
+// `Select(...)`, MySQL 8 and later. The window is a definition in 
+// parentheses, or the name of a window of the "windows" option.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // SELECT `id`, `user_id`, ROW_NUMBER() OVER (PARTITION BY user_id 
+//   //   ORDER BY created_at DESC) AS `rn` FROM `orders`
+//   mysql.Select("orders", nil, _json{"columns": []interface{}{
+//     "id", "user_id",
+//     mysql.Over("ROW_NUMBER()", "PARTITION BY user_id ORDER BY created_at DESC", "rn"),
+//   }})
+//
+//   // SELECT `id`, SUM(total) OVER `w` AS `running_total` FROM `orders` 
+//   //   WINDOW `w` AS (PARTITION BY user_id ORDER BY created_at)
+//   mysql.Select("orders", nil, _json{
+//     "columns": []interface{}{"id", mysql.Over("SUM(total)", "w", "running_total")},
+//     "windows": map[string]string{"w": "PARTITION BY user_id ORDER BY created_at"},
+//   })
+func Over(function, window, alias string) *Expression {
+  if is_identifier(window) {
+    window = EscapeId(window, true)
+  } else {
+    window = "(" + window + ")"
+  }
+  query := function + " OVER " + window
+  if alias != "" { query += " AS " + EscapeId(alias, true) }
+  return Raw(query)
+}
+
+// Returns the WINDOW clause of the "windows" option, ordered by name.
+func window_query(options map[string]interface{}) string {
+  var windows map[string]string
+  switch value := options["windows"].(type) {
+  case map[string]string: windows = value
+  case nil:               return ""
+  default:
+    panic(fmt.Errorf("mysql: invalid windows option type %T", value))
+  }
+  if len(windows) == 0 { return "" }
+
+  names := make([]string, 0, len(windows))
+  for name := range windows { names = append(names, name) }
+  sort.Strings(names)
+  for i, name := range names {
+    names[i] = EscapeId(name, true) + " AS (" + windows[name] + ")"
+  }
+  return " WINDOW " + strings.Join(names, ", ")
+}
+
+func is_identifier(s string) bool {
+  if s == "" || !is_name_start(s[0]) { return false }
+  for i := 1; i < len(s); i++ {
+    if !is_name_char(s[i]) { return false }
+  }
+  return true
+}
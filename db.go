@@ -0,0 +1,309 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Returned by `Tx.Commit()` when a nested transaction was rolled back, so the 
+// whole transaction is rolled back instead of committed.
+var ErrRollbackOnly = errors.New("mysql: transaction was rolled back by a nested transaction")
+
+// Handle which runs the API of this package on the connection pool or on a 
+// transaction, with a context. The package-level functions use the default 
+// handle connected by `Init(...)`.
+type DB struct {
+  pool     *atomic.Pointer[sql.DB]
+  tx       *Tx
+  // Connection of the pool which runs every query, for session state
+  conn     *sql.Conn
+  ctx      context.Context
+  analytic bool
+}
+
+// Transaction handle. It has the same methods as `DB`, and every query of it 
+// runs inside the transaction.
+type Tx struct {
+  *DB
+  tx     *sql.Tx
+  state  *tx_state
+  nested bool
+  // Set when a nested transaction committed, so a deferred `Rollback()` 
+  // doesn't roll back the outer one
+  done   bool
+}
+
+type tx_state struct {
+  rollback_only atomic.Bool
+}
+
+type tx_key struct{}
+
+var std = &DB{pool: &db}
+
+type runner interface {
+  QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+  ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+  QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Returns a handle whose queries use `ctx`. When `ctx` carries a transaction 
+// started by `BeginTxContext(...)`, queries run inside of it. Service code 
+// using this handle doesn't need to know whether it's called in a 
+// transaction.
+//
+// Example:
+//   func CreateOrder(ctx context.Context, order _json) int64 {
+//     result := mysql.WithContext(ctx).Insert("orders", order)
+//     id, _  := result.LastInsertId()
+//     return id
+//   }
+func (d *DB) WithContext(ctx context.Context) *DB {
+  handle := &DB{pool: d.pool, tx: d.tx, conn: d.conn, ctx: ctx, analytic: d.analytic}
+  if tx := TxFromContext(ctx); tx != nil { handle.tx = tx }
+  return handle
+}
+
+// Starts a transaction. Beginning a transaction on a `Tx` handle returns a 
+// nested transaction which joins the outer one, see `Tx.Commit()`. A nested 
+// transaction keeps the options of the outer one.
+//
+// Options:
+//   - `isolation`: string, isolation level "READ UNCOMMITTED", 
+//                  "READ COMMITTED", "REPEATABLE READ" (the default of 
+//                  InnoDB) or "SERIALIZABLE", or a `sql.IsolationLevel`
+//   - `read_only`: bool, start a read-only transaction, e.g. for reports
+//
+// Example:
+//   tx := mysql.Begin()
+//   defer tx.Rollback()
+//   tx.Insert("orders", order)
+//   tx.Update("stocks", _json{"amount": mysql.Raw("amount - 1")}, where)
+//   if err := tx.Commit(); err != nil { return err }
+//
+//   report := mysql.Begin(_json{"isolation": "REPEATABLE READ", "read_only": true})
+func (d *DB) Begin(options ...map[string]interface{}) *Tx {
+  if d.tx != nil { return d.tx.join(d.context()) }
+
+  var tx  *sql.Tx
+  var err error
+  if d.conn != nil {
+    tx, err = d.conn.BeginTx(d.context(), tx_options(options))
+  } else {
+    tx, err = d.pool.Load().BeginTx(d.context(), tx_options(options))
+  }
+  if err != nil { panic(err) }
+  handle := &Tx{tx: tx, state: &tx_state{}}
+  handle.DB = &DB{pool: d.pool, tx: handle, conn: d.conn, ctx: d.ctx, analytic: d.analytic}
+  return handle
+}
+
+// Starts a transaction and returns a context carrying it, so handles created 
+// by `WithContext(...)` from the returned context run their queries inside the 
+// transaction. When `ctx` already carries a transaction, a nested transaction 
+// joining it is returned. Options are the options of `Begin(...)`.
+//
+// Example:
+//   ctx, tx := mysql.BeginTxContext(ctx)
+//   defer tx.Rollback()
+//   id := CreateOrder(ctx, order) // runs inside the transaction
+//   ReserveStock(ctx, id)
+//   return tx.Commit()
+func (d *DB) BeginTxContext(
+  ctx context.Context,
+  options ...map[string]interface{},
+) (context.Context, *Tx) {
+  if outer := TxFromContext(ctx); outer != nil {
+    return ctx, outer.join(ctx)
+  }
+  tx := d.WithContext(ctx).Begin(options...)
+  return context.WithValue(ctx, tx_key{}, tx), tx
+}
+
+// Runs `fn` inside a transaction carried by the context passed to it. The 
+// transaction is committed when `fn` returns nil, otherwise or when `fn` 
+// panics it's rolled back. Options are the options of `Begin(...)`.
+//
+// Example:
+//   err := mysql.Transaction(ctx, func(ctx context.Context) error {
+//     id := CreateOrder(ctx, order)
+//     return ReserveStock(ctx, id)
+//   })
+func (d *DB) Transaction(
+  ctx context.Context,
+  fn func(ctx context.Context) error,
+  options ...map[string]interface{},
+) error {
+  ctx, tx := d.BeginTxContext(ctx, options...)
+  defer tx.Rollback()
+  if err := fn(ctx); err != nil { return err }
+  return tx.Commit()
+}
+
+var isolation_levels = map[string]sql.IsolationLevel{
+  "READ UNCOMMITTED": sql.LevelReadUncommitted,
+  "READ COMMITTED":   sql.LevelReadCommitted,
+  "REPEATABLE READ":  sql.LevelRepeatableRead,
+  "SERIALIZABLE":     sql.LevelSerializable,
+}
+
+// Maps the options of `Begin(...)` to `sql.TxOptions`.
+func tx_options(args []map[string]interface{}) *sql.TxOptions {
+  if len(args) == 0 || args[0] == nil { return nil }
+  options := &sql.TxOptions{}
+  switch isolation := args[0]["isolation"].(type) {
+  case nil:
+  case sql.IsolationLevel:
+    options.Isolation = isolation
+  case string:
+    name  := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(isolation, "_", " ")), " "))
+    level, ok := isolation_levels[name]
+    if !ok { panic(fmt.Errorf("mysql: unsupported isolation level %q", isolation)) }
+    options.Isolation = level
+  default:
+    panic(fmt.Errorf("mysql: invalid isolation option type %T", isolation))
+  }
+  options.ReadOnly, _ = args[0]["read_only"].(bool)
+  return options
+}
+
+// Returns the transaction carried by `ctx`, or nil.
+func TxFromContext(ctx context.Context) *Tx {
+  tx, _ := ctx.Value(tx_key{}).(*Tx)
+  return tx
+}
+
+// Commits the transaction. Committing a nested transaction does nothing, the 
+// outermost transaction commits. When a nested transaction was rolled back, 
+// the transaction is rolled back and `ErrRollbackOnly` is returned.
+func (t *Tx) Commit() error {
+  if t.nested {
+    t.done = true
+    return nil
+  }
+  if t.state.rollback_only.Load() {
+    if err := t.tx.Rollback(); err != nil && err != sql.ErrTxDone { return err }
+    return ErrRollbackOnly
+  }
+  return t.tx.Commit()
+}
+
+// Rolls back the transaction. Rolling back a nested transaction marks the 
+// outermost one to be rolled back on commit. It's safe to call after 
+// `Commit()`, so it can be deferred, and does nothing then.
+func (t *Tx) Rollback() error {
+  if t.nested {
+    if t.done { return nil }
+    t.done = true
+    t.state.rollback_only.Store(true)
+    return nil
+  }
+  err := t.tx.Rollback()
+  if err == sql.ErrTxDone { return nil }
+  return err
+}
+
+// Returns statistics of the connection pool of this handle.
+func (d *DB) Stats() sql.DBStats {
+  pool := d.pool.Load()
+  if pool == nil { return sql.DBStats{} }
+  return pool.Stats()
+}
+
+func (t *Tx) join(ctx context.Context) *Tx {
+  handle := &Tx{tx: t.tx, state: t.state, nested: true}
+  handle.DB = &DB{pool: t.pool, tx: handle, conn: t.conn, ctx: ctx, analytic: t.analytic}
+  return handle
+}
+
+func (d *DB) context() context.Context {
+  if d.ctx == nil { return context.Background() }
+  return d.ctx
+}
+
+func (d *DB) runner() runner {
+  if d.tx != nil   { return d.tx.tx }
+  if d.conn != nil { return d.conn }
+  return d.pool.Load()
+}
+
+// Calls `fn` with a handle whose queries run on the same connection, needed 
+// for session state like user variables. Handles in a transaction already do.
+func (d *DB) with_conn(fn func(handle *DB)) {
+  if d.tx != nil || d.conn != nil {
+    fn(d)
+    return
+  }
+  conn, err := d.pool.Load().Conn(d.context())
+  if err != nil { panic(err) }
+  defer conn.Close()
+  fn(&DB{pool: d.pool, conn: conn, ctx: d.ctx, analytic: d.analytic})
+}
+
+func (d *DB) query(query string, values ...interface{}) (*sql.Rows, error) {
+  query = d.tag_query(query)
+  values, err := encode_values(values)
+  if err != nil { return nil, err }
+  if Debug { Logger.Println(query, redact(query, values)) }
+  // Hooks run before the guard, so a panicking hook doesn't leave a probe 
+  // of `QueryBreaker` running forever, and results are recorded before the 
+  // after hooks
+  before_query(query, values)
+  record, err := d.guard()
+  if err != nil { return nil, err }
+  start := time.Now()
+  rows, err := d.runner().QueryContext(d.context(), query, values...)
+  record(err)
+  after_query(query, values, start, err)
+  return rows, err
+}
+
+func (d *DB) exec(query string, values ...interface{}) (sql.Result, error) {
+  query = d.tag_query(query)
+  values, err := encode_values(values)
+  if err != nil { return nil, err }
+  if Debug { Logger.Println(query, redact(query, values)) }
+  before_query(query, values)
+  record, err := d.guard()
+  if err != nil { return nil, err }
+  start := time.Now()
+  result, err := d.runner().ExecContext(d.context(), query, values...)
+  record(err)
+  after_query(query, values, start, err)
+  return result, err
+}
+
+func (d *DB) scan_row(dest []interface{}, query string, values ...interface{}) error {
+  query = d.tag_query(query)
+  values, err := encode_values(values)
+  if err != nil { return err }
+  if Debug { Logger.Println(query, redact(query, values)) }
+  before_query(query, values)
+  record, err := d.guard()
+  if err != nil { return err }
+  start := time.Now()
+  err    = d.runner().QueryRowContext(d.context(), query, values...).Scan(dest...)
+  record(err)
+  after_query(query, values, start, err)
+  return err
+}
+
+func (d *DB) query_row(query string, values ...interface{}) *sql.Row {
+  query = d.tag_query(query)
+  values, err := encode_values(values)
+  if err != nil { panic(err) }
+  if Debug { Logger.Println(query, redact(query, values)) }
+  before_query(query, values)
+  record, err := d.guard()
+  if err != nil { panic(err) }
+  start := time.Now()
+  row   := d.runner().QueryRowContext(d.context(), query, values...)
+  record(row.Err())
+  after_query(query, values, start, row.Err())
+  return row
+}
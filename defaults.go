@@ -0,0 +1,38 @@
+package mysql
+
+import "errors"
+
+// Registers default column values of a table which are merged into data maps 
+// of `Insert`, `InsertIgnore`, `Replace`, `InsertRow` and `Upsert` when the 
+// column is absent. A value of type `func() interface{}` is called for every 
+// inserted row, e.g. to generate a UUID. Registering a table again replaces 
+// its defaults, nil removes them.
+//
+// It's a shortcut for the `Defaults` field of the table's `Model`, the model 
+// is registered when it doesn't exist yet.
+//
+// Example:
+//   mysql.RegisterDefaults("orders", map[string]interface{}{
+//     "status": "pending",
+//     "uuid":   func() interface{} { return uuid.NewString() },
+//   })
+//   mysql.Insert("orders", _json{"user_id": user_id}) // with status and uuid
+func RegisterDefaults(table string, defaults map[string]interface{}) {
+  if table == "" { panic(errors.New("mysql: defaults without table name")) }
+
+  var copied map[string]interface{}
+  if defaults != nil {
+    copied = make(map[string]interface{}, len(defaults))
+    for column, value := range defaults { copied[column] = value }
+  }
+
+  // Registered models are never mutated, readers keep the model they got
+  models.Lock()
+  defer models.Unlock()
+  model := Model{Table: table, PrimaryKey: "id"}
+  if current := models.tables[table]; current != nil { model = *current }
+  model.Defaults = copied
+
+  if models.tables == nil { models.tables = map[string]*Model{} }
+  models.tables[table] = &model
+}
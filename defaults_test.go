@@ -0,0 +1,25 @@
+package mysql_test
+
+import (
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+func TestRegisterDefaults(t *testing.T) {
+  mysql.RegisterModel(mysql.Model{Table: "defaults_orders", SoftDelete: "deleted_at"})
+  defaults := map[string]interface{}{"status": "pending"}
+  mysql.RegisterDefaults("defaults_orders", defaults)
+  registered := mysql.ModelOf("defaults_orders")
+
+  defaults["status"] = "changed"
+  mysql.RegisterDefaults("defaults_orders", map[string]interface{}{"status": "paid"})
+
+  if got := registered.Defaults["status"]; got != "pending" {
+    t.Fatalf("registered defaults were mutated, status = %v", got)
+  }
+  model := mysql.ModelOf("defaults_orders")
+  if model.SoftDelete != "deleted_at" || model.Defaults["status"] != "paid" {
+    t.Fatalf("model = %+v", *model)
+  }
+}
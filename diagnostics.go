@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Queries taking longer than this are kept in the slow query log exposed by 
+// `DiagnosticsHandler()`. Set to 0 to disable recording.
+var SlowQueryThreshold = 500 * time.Millisecond
+
+// Maximum number of entries kept in the slow query log.
+var SlowQueryLogSize = 100
+
+// Slow query log entry.
+type SlowQuery struct {
+  Query    string        `json:"query"`
+  Duration time.Duration `json:"duration"`
+  Time     time.Time     `json:"time"`
+  Error    string        `json:"error,omitempty"`
+}
+
+var slow_queries struct {
+  sync.Mutex
+  entries []SlowQuery
+}
+
+// Returns a copy of the slow query log, most recent first.
+func SlowQueries() []SlowQuery {
+  slow_queries.Lock()
+  defer slow_queries.Unlock()
+
+  entries := make([]SlowQuery, len(slow_queries.entries))
+  for i, entry := range slow_queries.entries {
+    entries[len(entries)-1-i] = entry
+  }
+  return entries
+}
+
+// Returns an `http.Handler` which serves diagnostic information as JSON, to be 
+// mounted on an internal admin server of a service.
+//
+// Routes:
+//   - `/slow-queries`: recent queries slower than `SlowQueryThreshold`
+//   - `/pool`: connection pool statistics, see `sql.DBStats`
+//   - `/schema`: schema cache statistics and tables of the default connection 
+//                held in it
+//   - `/server`: server settings, see `ServerInfo()`
+//
+// Example:
+//   admin := http.NewServeMux()
+//   admin.Handle("/debug/mysql/", http.StripPrefix("/debug/mysql", mysql.DiagnosticsHandler()))
+//   go http.ListenAndServe("127.0.0.1:6060", admin)
+func DiagnosticsHandler() http.Handler {
+  mux := http.NewServeMux()
+  mux.HandleFunc("/slow-queries", func(w http.ResponseWriter, r *http.Request) {
+    write_json(w, SlowQueries())
+  })
+  mux.HandleFunc("/pool", func(w http.ResponseWriter, r *http.Request) {
+    write_json(w, std.Stats())
+  })
+  mux.HandleFunc("/schema", func(w http.ResponseWriter, r *http.Request) {
+    write_json(w, map[string]interface{}{
+      "stats":  SchemaCacheStats(),
+      "tables": schema_cache_state(),
+    })
+  })
+  mux.HandleFunc("/server", func(w http.ResponseWriter, r *http.Request) {
+    defer func() {
+      if err := recover(); err != nil {
+        http.Error(w, "server info is unavailable", http.StatusServiceUnavailable)
+      }
+    }()
+    write_json(w, ServerInfo())
+  })
+  return mux
+}
+
+func write_json(w http.ResponseWriter, value interface{}) {
+  w.Header().Set("Content-Type", "application/json")
+  encoder := json.NewEncoder(w)
+  encoder.SetIndent("", "  ")
+  encoder.Encode(value)
+}
+
+func record_query(query string, start time.Time, err error) {
+  duration := time.Since(start)
+  if SlowQueryThreshold <= 0 || duration < SlowQueryThreshold { return }
+
+  entry := SlowQuery{Query: query, Duration: duration, Time: start}
+  if err != nil { entry.Error = err.Error() }
+
+  slow_queries.Lock()
+  defer slow_queries.Unlock()
+  slow_queries.entries = append(slow_queries.entries, entry)
+  if overflow := len(slow_queries.entries) - SlowQueryLogSize; overflow > 0 {
+    slow_queries.entries = slow_queries.entries[overflow:]
+  }
+}
@@ -0,0 +1,18 @@
+package mysql_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+func TestDiagnosticsPoolWithoutConnection(t *testing.T) {
+  previous := mysql.InitDB(nil)
+  defer mysql.InitDB(previous)
+
+  recorder := httptest.NewRecorder()
+  mysql.DiagnosticsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/pool", nil))
+  if recorder.Code != http.StatusOK { t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK) }
+}
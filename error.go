@@ -1,9 +1,123 @@
 package mysql
 
-import m "github.com/go-sql-driver/mysql"
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
+	m "github.com/go-sql-driver/mysql"
+)
+
+// Returned by `Find(...)`, `ScanOne(...)` and `ExecQueryRow(...).Scan(...)` 
+// when no row matches. It's `sql.ErrNoRows`, so both can be compared.
+var ErrNoRows = sql.ErrNoRows
+
+// Panics of updates with optimistic locking when the row was changed since it 
+// was read, or doesn't exist anymore, see `Model.Version`. `UpdateCount(...)` 
+// returns it.
+var ErrStaleRow = errors.New("mysql: row was changed by another update")
+
+// Panics of where maps with an empty slice when `EmptyInError` is set, 
+// returned by the APIs returning errors. It's wrapped with the column, compare 
+// with `errors.Is(err, mysql.ErrEmptyIn)`.
+var ErrEmptyIn = errors.New("mysql: empty slice in IN condition")
+
+// Error of a query which failed on the server. It wraps the driver error, so 
+// `errors.As(err, &mysql_err)` with a `*mysql.MySQLError` of the driver 
+// matches it.
 type Error struct {
   Query      string
+  // Values of the query, masked like in `Debug` logs, see `RedactColumns`
   Values     []interface{}
   MySQLError *m.MySQLError
-}
\ No newline at end of file
+}
+
+// Returns the server error with the query. Values are redacted to their 
+// types, so secrets don't end up in logs.
+func (e *Error) Error() string {
+  message := fmt.Sprintf("mysql: %v; query: %s", e.MySQLError, e.Query)
+  if len(e.Values) > 0 {
+    message += "; values: [" + strings.Join(redact_values(e.Values), ", ") + "]"
+  }
+  return message
+}
+
+// Returns the driver error.
+func (e *Error) Unwrap() error { return e.MySQLError }
+
+func redact_values(values []interface{}) []string {
+  redacted := make([]string, len(values))
+  for i, value := range values {
+    switch value := value.(type) {
+    case nil:    redacted[i] = "NULL"
+    case string: redacted[i] = fmt.Sprintf("string(%d)", len(value))
+    case []byte: redacted[i] = fmt.Sprintf("[]byte(%d)", len(value))
+    default:     redacted[i] = fmt.Sprintf("%T", value)
+    }
+  }
+  return redacted
+}
+
+// Reports whether `err` is a duplicate entry for a PRIMARY KEY or UNIQUE 
+// index.
+//
+// Example:
+//   err := mysql.WrapFunc(func() error {
+//     mysql.Insert("users", _json{"email": email})
+//     return nil
+//   })
+//   if mysql.IsDuplicateKey(err) { return ErrEmailTaken }
+func IsDuplicateKey(err error) bool {
+  return has_error_code(err, 1022, 1062, 1586)
+}
+
+// Reports whether `err` is a deadlock, the transaction was rolled back and 
+// can be retried.
+func IsDeadlock(err error) bool {
+  return has_error_code(err, 1213)
+}
+
+// Reports whether `err` is a lock wait timeout.
+func IsLockWaitTimeout(err error) bool {
+  return has_error_code(err, 1205)
+}
+
+// Reports whether `err` is a foreign key violation, inserting a row without 
+// its parent row or deleting a parent row which is still referenced.
+func IsForeignKeyViolation(err error) bool {
+  return has_error_code(err, 1216, 1217, 1451, 1452)
+}
+
+// Reports whether `err` is `ErrNoRows`.
+func IsNotFound(err error) bool {
+  return errors.Is(err, ErrNoRows)
+}
+
+func has_error_code(err error, codes ...uint16) bool {
+  var mysql_err *m.MySQLError
+  if !errors.As(err, &mysql_err) { return false }
+  for _, code := range codes {
+    if mysql_err.Number == code { return true }
+  }
+  return false
+}
+
+// Converts a panic of this package into an error for functions returning 
+// errors, when it's deferred. Panics which are not errors, like bugs, are 
+// propagated. Packages built on this one, like `migrate`, use it as well.
+//
+// Example:
+//   func create_user(email string) (result sql.Result, err error) {
+//     defer mysql.RecoverError(&err)
+//     return mysql.InsertRow("users", _json{"email": email}), nil
+//   }
+func RecoverError(err *error) {
+  if r := recover(); r != nil {
+    if e, ok := r.(error); ok {
+      *err = e
+      return
+    }
+    panic(r)
+  }
+}
@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Returns the execution plan of the query `Select(...)` would run, for quick 
+// index diagnostics. Options are the options of `Select(...)`, and:
+//   - `format`: string, "json" for `EXPLAIN FORMAT=JSON`. The plan is then a 
+//               single row with the decoded JSON plan in its "EXPLAIN" column
+//
+// Returns:
+//   - []map[string]interface{}: rows of the plan like "table", "type", "key" 
+//                               and "rows"
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   for _, row := range mysql.Explain("orders", _json{"user_id": 1}) {
+//     log.Println(row["table"], row["type"], row["key"], row["rows"])
+//   }
+func (d *DB) Explain(
+  table string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) []map[string]interface{} {
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+  query, values := d.build_select_query(table, where, options)
+  format, _ := options["format"].(string)
+  return d.explain(query, values, format)
+}
+
+// Returns the execution plan of a query, see `Explain(...)`.
+//
+// Example:
+//   plan := mysql.ExplainQuery("SELECT * FROM orders WHERE user_id = ?", 1)
+func (d *DB) ExplainQuery(query string, values ...interface{}) []map[string]interface{} {
+  return d.explain(query, values, "")
+}
+
+func (d *DB) explain(query string, values []interface{}, format string) []map[string]interface{} {
+  options := map[string]interface{}{}
+  explain := "EXPLAIN "
+  switch strings.ToLower(format) {
+  case "", "traditional":
+  case "json":
+    explain = "EXPLAIN FORMAT=JSON "
+    options["json"] = []string{"EXPLAIN"}
+  default:
+    panic(fmt.Errorf("mysql: unsupported explain format %q", format))
+  }
+
+  rows := d.ExecQuery(explain + query, values...)
+  defer rows.Close()
+  return scan_maps(rows, options)
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import "strings"
+
+// Raw SQL expression which is written into the generated query as is, instead 
+// of being sent as a placeholder value. Created by `Raw(...)` or `Expr(...)`.
+type Expression struct {
+  query    string
+  values   []interface{}
+  // Parenthesized SELECT statement created by `Subquery(...)`
+  subquery bool
+}
+
+// Creates a raw SQL expression usable as a value in data maps of 
+// `Insert/Update` and in where maps. The expression may contain `?` 
+// placeholders for the given `values`.
+//
+// Never build the expression from user input, pass it as `values` instead.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // UPDATE `posts` SET `views` = views + 1 WHERE `id` = ?
+//   mysql.Update("posts", _json{"views": mysql.Raw("views + 1")}, _json{"id": id})
+//
+//   // SELECT * FROM `tokens` WHERE `expires_at` < NOW()
+//   mysql.Select("tokens", _json{"expires_at <": mysql.Raw("NOW()")})
+//
+//   // UPDATE `users` SET `score` = score * ? WHERE ...
+//   mysql.Update("users", _json{"score": mysql.Raw("score * ?", 2)}, where)
+func Raw(query string, values ...interface{}) *Expression {
+  return &Expression{query: query, values: values}
+}
+
+// Alias of `Raw(...)`, reads better for function calls like 
+// `mysql.Expr("NOW()")`.
+func Expr(query string, values ...interface{}) *Expression {
+  return Raw(query, values...)
+}
+
+// Creates a subquery usable as a value in where maps, with the operators 
+// `IN`, `NOT IN` or comparisons, and as the source of `Select(...)` with the 
+// option "from". Placeholder values of the subquery are passed in the order 
+// of the generated query.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // SELECT * FROM `posts` WHERE `user_id` NOT IN (SELECT user_id FROM 
+//   //   banned_users WHERE until > ?) AND `published` = ?
+//   banned := mysql.Subquery("SELECT user_id FROM banned_users WHERE until > ?", now)
+//   rows   := mysql.Select("posts", _json{"user_id NOT IN": banned, "published": 1})
+//
+//   // SELECT * FROM (SELECT user_id, COUNT(*) AS total FROM orders 
+//   //   GROUP BY user_id) AS `totals` WHERE `total` > ?
+//   rows = mysql.Select("totals", _json{"total >": 10}, _json{
+//     "from": mysql.Subquery("SELECT user_id, COUNT(*) AS total FROM orders GROUP BY user_id"),
+//   })
+func Subquery(query string, values ...interface{}) *Expression {
+  query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+  return &Expression{query: "(" + query + ")", values: values, subquery: true}
+}
+
+// Returns the SQL of the expression.
+func (e *Expression) String() string { return e.query }
+
+// Returns the placeholder values of the expression.
+func (e *Expression) Values() []interface{} { return e.values }
+
+func (e *Expression) condition(column, operator string) (string, []interface{}) {
+  query := e.query
+  switch operator {
+  case "":             operator = "="
+  case "IN", "NOT IN":
+    if !e.subquery { query = "(" + query + ")" }
+  }
+  return column + " " + operator + " " + query, e.values
+}
@@ -0,0 +1,110 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	m "github.com/go-sql-driver/mysql"
+)
+
+// Interval after which connecting to `Config.Host` is tried again, once the 
+// pool failed over to one of `Config.Hosts`. New connections go back to the 
+// first host when it's reachable again, set `Config.ConnMaxLifetime` so 
+// existing connections to a failover host are replaced as well.
+var FailbackInterval = 30 * time.Second
+
+// Connector trying every host in order until one can be connected.
+type failover_connector struct {
+  hosts      []string
+  connectors []driver.Connector
+  active     atomic.Int32
+  // Time of the last attempt to connect to the first host, in nanoseconds
+  failback   atomic.Int64
+}
+
+// Builds the connector of a connection pool, with failover when 
+// `Config.Hosts` is given.
+func new_connector(cfg *Config) driver.Connector {
+  if len(cfg.Hosts) == 0 || cfg.Socket != "" {
+    connector, err := m.NewConnector(driver_config(cfg))
+    if err != nil { panic(err) }
+    return connector
+  }
+
+  failover := &failover_connector{}
+  hosts    := append([]string{cfg.Host}, cfg.Hosts...)
+  for _, address := range hosts {
+    host_cfg := *cfg
+    host_cfg.Host       = address
+    host_cfg.AutoSocket = false
+    if host, port, err := net.SplitHostPort(address); err == nil {
+      value, err := strconv.ParseInt(port, 10, 16)
+      if err != nil { panic(err) }
+      host_cfg.Host = host
+      host_cfg.Port = int16(value)
+    }
+
+    connector, err := m.NewConnector(driver_config(&host_cfg))
+    if err != nil { panic(err) }
+    failover.hosts      = append(failover.hosts, address)
+    failover.connectors = append(failover.connectors, connector)
+  }
+  return failover
+}
+
+func (c *failover_connector) Connect(ctx context.Context) (driver.Conn, error) {
+  active := int(c.active.Load())
+  order  := make([]int, 0, len(c.connectors))
+  if active != 0 {
+    last := time.Unix(0, c.failback.Load())
+    if time.Since(last) >= FailbackInterval {
+      c.failback.Store(time.Now().UnixNano())
+      order = append(order, 0)
+    }
+  }
+  order = append(order, active)
+  for i := range c.connectors {
+    if !contains_int(order, i) { order = append(order, i) }
+  }
+
+  var err error
+  for _, i := range order {
+    var conn driver.Conn
+    if conn, err = c.connect(ctx, i); err == nil {
+      if previous := int(c.active.Swap(int32(i))); previous != i {
+        Logger.Printf("mysql: switched from host %s to %s", c.hosts[previous], c.hosts[i])
+      }
+      return conn, nil
+    }
+    if ctx.Err() != nil { break }
+  }
+  return nil, err
+}
+
+// Connects to a host and checks it's healthy.
+func (c *failover_connector) connect(ctx context.Context, i int) (driver.Conn, error) {
+  conn, err := c.connectors[i].Connect(ctx)
+  if err != nil { return nil, err }
+  if pinger, ok := conn.(driver.Pinger); ok {
+    if err := pinger.Ping(ctx); err != nil {
+      conn.Close()
+      return nil, err
+    }
+  }
+  return conn, nil
+}
+
+func (c *failover_connector) Driver() driver.Driver {
+  return c.connectors[0].Driver()
+}
+
+func contains_int(values []int, value int) bool {
+  for _, v := range values {
+    if v == value { return true }
+  }
+  return false
+}
@@ -0,0 +1,109 @@
+// Fixture loader on the connection of package 
+// `github.com/je3f0o/go-jeefo-mysql`, for fast integration test setup. 
+// Fixtures are rows by table, read from YAML or JSON files or written as Go 
+// maps. Loading empties the tables first, and orders them by their foreign 
+// keys, see `mysql.SortTables(...)`.
+//
+// Example fixtures/users.yml:
+//   users:
+//     - id: 1
+//       name: jeefo
+//   orders:
+//     - id: 10
+//       user_id: 1
+//       total: 990
+//
+// Example test:
+//   func TestOrders(t *testing.T) {
+//     ctx := fixtures.Use(t, fixtures.MustReadFile("fixtures/users.yml"))
+//     orders := ListOrders(ctx, 1)
+//     // code...
+//   }
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"gopkg.in/yaml.v3"
+)
+
+// Rows to insert by table name.
+type Fixtures map[string][]map[string]interface{}
+
+// Reads fixtures of a YAML or JSON file, the format is chosen by the file 
+// extension ".yml", ".yaml" or ".json".
+func ReadFile(path string) (Fixtures, error) {
+  content, err := os.ReadFile(path)
+  if err != nil { return nil, err }
+
+  fixtures := Fixtures{}
+  switch strings.ToLower(filepath.Ext(path)) {
+  case ".yml", ".yaml":
+    err = yaml.Unmarshal(content, &fixtures)
+  case ".json":
+    err = json.Unmarshal(content, &fixtures)
+  default:
+    return nil, fmt.Errorf("fixtures: unsupported file type %q", path)
+  }
+  if err != nil { return nil, fmt.Errorf("fixtures: %s: %w", path, err) }
+  return fixtures, nil
+}
+
+// Same with `ReadFile(...)` except it panics on errors, for test setup.
+func MustReadFile(path string) Fixtures {
+  fixtures, err := ReadFile(path)
+  if err != nil { panic(err) }
+  return fixtures
+}
+
+// Returns fixtures of every given set, rows of the same table are appended.
+func Merge(sets ...Fixtures) Fixtures {
+  merged := Fixtures{}
+  for _, set := range sets {
+    for table, rows := range set { merged[table] = append(merged[table], rows...) }
+  }
+  return merged
+}
+
+// Empties the tables of the fixtures and inserts their rows with 
+// `mysql.Insert(...)`, so defaults and models apply, in a transaction. Tables 
+// are emptied with DELETE in reverse foreign key order, which unlike TRUNCATE 
+// works inside a transaction. When `ctx` carries a transaction, the fixtures 
+// are loaded in it.
+func Load(ctx context.Context, fixtures Fixtures) (err error) {
+  defer mysql.RecoverError(&err)
+
+  tables := make([]string, 0, len(fixtures))
+  for table := range fixtures { tables = append(tables, table) }
+  tables = mysql.SortTables(tables...)
+
+  return mysql.Transaction(ctx, func(ctx context.Context) error {
+    handle := mysql.WithContext(ctx)
+    for i := len(tables) - 1; i >= 0; i-- {
+      handle.Exec("DELETE FROM " + mysql.EscapeId(tables[i]) + ";")
+    }
+    for _, table := range tables {
+      for _, row := range fixtures[table] { handle.Insert(table, row) }
+    }
+    return nil
+  })
+}
+
+// Loads fixtures in a transaction scoped to the test, which is rolled back 
+// when the test ends, and returns the context carrying it. Code under test 
+// sees the fixtures through `mysql.WithContext(ctx)`, and tests don't see the 
+// changes of each other.
+func Use(t testing.TB, fixtures Fixtures) context.Context {
+  t.Helper()
+  ctx, tx := mysql.BeginTxContext(context.Background())
+  t.Cleanup(func() { tx.Rollback() })
+  if err := Load(ctx, fixtures); err != nil { t.Fatal(err) }
+  return ctx
+}
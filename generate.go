@@ -0,0 +1,292 @@
+package mysql
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"unicode"
+)
+
+var initialisms = map[string]string{
+  "id": "ID", "ip": "IP", "url": "URL", "uri": "URI", "uuid": "UUID",
+  "api": "API", "json": "JSON", "html": "HTML", "http": "HTTP", "sql": "SQL",
+}
+
+// Generates Go source code with a string type per `ENUM` column of the given 
+// tables, a constant per enum value and validation methods. The types 
+// implement `sql.Scanner` and `driver.Valuer`, so they can be scanned 
+// directly with `rows.Scan(...)` and used as values in data and where maps, 
+// and invalid values are rejected in both directions. NULL is scanned into 
+// the zero value, which is written as NULL. `GenerateModels(...)` uses the 
+// types for the fields of ENUM columns.
+//
+// Parameters:
+//   - `w`: destination of the generated, gofmt formatted source
+//   - `pkg`: package name of the generated file
+//   - `tables`: tables to read `ENUM` columns from
+//
+// Example, with a small generator program run by `go generate`:
+//   //go:generate go run ./cmd/enums
+//
+//   // cmd/enums/main.go
+//   mysql.Init(cfg)
+//   f, _ := os.Create("models/enums_gen.go")
+//   defer f.Close()
+//   if err := mysql.GenerateEnums(f, "models", "orders", "users"); err != nil {
+//     log.Fatal(err)
+//   }
+//
+// For column `orders.status enum('pending','paid')` it generates:
+//   type OrdersStatus string
+//
+//   const (
+//     OrdersStatusPending OrdersStatus = "pending"
+//     OrdersStatusPaid    OrdersStatus = "paid"
+//   )
+//
+//   func (v OrdersStatus) Valid() bool
+//   func (v *OrdersStatus) Scan(src interface{}) error
+//   func (v OrdersStatus) Value() (driver.Value, error)
+func GenerateEnums(w io.Writer, pkg string, tables ...string) (err error) {
+  defer RecoverError(&err)
+
+  var src bytes.Buffer
+  fmt.Fprintf(&src, "// Code generated by GenerateEnums. DO NOT EDIT.\n\n")
+  fmt.Fprintf(&src, "package %s\n\n", pkg)
+  fmt.Fprintf(&src, "import (\n\"database/sql/driver\"\n\"fmt\"\n)\n")
+
+  for _, table := range tables {
+    for _, column := range Columns(table) {
+      if column.DataType != "enum" { continue }
+      write_enum(&src, go_name(table)+go_name(column.Name), table, &column)
+    }
+  }
+
+  formatted, err := format.Source(src.Bytes())
+  if err != nil { return err }
+  _, err = w.Write(formatted)
+  return err
+}
+
+func write_enum(w io.Writer, name, table string, column *Column) {
+  values := column.EnumValues()
+  fmt.Fprintf(w, "\n// %s is the ENUM column %s.%s.\n", name, table, column.Name)
+  fmt.Fprintf(w, "type %s string\n\nconst (\n", name)
+  for _, value := range values {
+    fmt.Fprintf(w, "%s%s %s = %q\n", name, go_name(value), name, value)
+  }
+  fmt.Fprintf(w, ")\n\n")
+
+  fmt.Fprintf(w, "// %sValues returns all values of %s.\n", name, name)
+  fmt.Fprintf(w, "func %sValues() []%s {\nreturn []%s{", name, name, name)
+  for _, value := range values {
+    fmt.Fprintf(w, "%s%s, ", name, go_name(value))
+  }
+  fmt.Fprintf(w, "}\n}\n\n")
+
+  fmt.Fprintf(w, "// Valid reports whether v is a value of the ENUM column.\n")
+  fmt.Fprintf(w, "func (v %s) Valid() bool {\nswitch v {\ncase ", name)
+  for i, value := range values {
+    if i > 0 { fmt.Fprintf(w, ", ") }
+    fmt.Fprintf(w, "%s%s", name, go_name(value))
+  }
+  fmt.Fprintf(w, ":\nreturn true\n}\nreturn false\n}\n\n")
+
+  fmt.Fprintf(w, `// Scan implements sql.Scanner, NULL is the zero value.
+func (v *%[1]s) Scan(src interface{}) error {
+var value %[1]s
+switch src := src.(type) {
+case nil:
+*v = ""
+return nil
+case string:
+value = %[1]s(src)
+case []byte:
+value = %[1]s(src)
+default:
+return fmt.Errorf("cannot scan %%T into %[1]s", src)
+}
+if !value.Valid() {
+return fmt.Errorf("invalid %[1]s value %%q", string(value))
+}
+*v = value
+return nil
+}
+
+// Value implements driver.Valuer, the zero value is NULL.
+func (v %[1]s) Value() (driver.Value, error) {
+if !v.Valid() {
+if v == "" {
+return nil, nil
+}
+return nil, fmt.Errorf("invalid %[1]s value %%q", string(v))
+}
+return string(v), nil
+}
+`, name)
+}
+
+// Generates Go source code with a struct per table, with a field and a `db` 
+// tag per column, and constants of the table and column names, so typed code 
+// stays in sync with the real database when it's generated again after 
+// migrations. The structs have a `TableName()` method for `AutoMigrate(...)`.
+//
+// Field types follow the column types, nullable columns are pointers:
+//   - `bool` for TINYINT(1), sized `int` and `uint` types for integers
+//   - `float32` for FLOAT, `float64` for DOUBLE
+//   - `string` for text, SET, DECIMAL and TIME to keep their values
+//   - the type of `GenerateEnums(...)` for ENUM, like `OrdersStatus`, so 
+//     generate the enums of the tables into the same package
+//   - `time.Time` for DATE, DATETIME and TIMESTAMP, which needs 
+//     `Config.ParseTime` to scan them
+//   - `json.RawMessage` for JSON, `[]byte` for binary and BIT columns
+//
+// Parameters:
+//   - `w`: destination of the generated, gofmt formatted source
+//   - `pkg`: package name of the generated file
+//   - `tables`: tables to generate, every table of the current database when 
+//     none is given
+//
+// Example, with a small generator program run by `go generate`:
+//   //go:generate go run ./cmd/models
+//
+//   // cmd/models/main.go
+//   mysql.Init(cfg)
+//   f, _ := os.Create("models/models_gen.go")
+//   defer f.Close()
+//   if err := mysql.GenerateModels(f, "models"); err != nil { log.Fatal(err) }
+//
+// For table `orders (id BIGINT UNSIGNED, user_id INT, note TEXT NULL)` it 
+// generates:
+//   type Orders struct {
+//     ID     uint64  `db:"id"`
+//     UserID int32   `db:"user_id"`
+//     Note   *string `db:"note"`
+//   }
+//
+//   func (Orders) TableName() string
+//
+//   const (
+//     OrdersTable        = "orders"
+//     OrdersColumnID     = "id"
+//     OrdersColumnUserID = "user_id"
+//     OrdersColumnNote   = "note"
+//   )
+func GenerateModels(w io.Writer, pkg string, tables ...string) (err error) {
+  defer RecoverError(&err)
+  if len(tables) == 0 { tables = std.table_names() }
+
+  imports := map[string]bool{}
+  var body bytes.Buffer
+  for _, table := range tables {
+    columns := Columns(table)
+    if len(columns) == 0 { return fmt.Errorf("mysql: table %s not found", table) }
+    write_model(&body, table, columns, imports)
+  }
+
+  var src bytes.Buffer
+  fmt.Fprintf(&src, "// Code generated by GenerateModels. DO NOT EDIT.\n\n")
+  fmt.Fprintf(&src, "package %s\n\n", pkg)
+  if len(imports) > 0 {
+    fmt.Fprintf(&src, "import (\n")
+    for _, path := range []string{"encoding/json", "time"} {
+      if imports[path] { fmt.Fprintf(&src, "%q\n", path) }
+    }
+    fmt.Fprintf(&src, ")\n")
+  }
+  src.Write(body.Bytes())
+
+  formatted, err := format.Source(src.Bytes())
+  if err != nil { return err }
+  _, err = w.Write(formatted)
+  return err
+}
+
+func write_model(w io.Writer, table string, columns []Column, imports map[string]bool) {
+  name := go_name(table)
+  fmt.Fprintf(w, "\n// %s is a row of the table %s.\n", name, table)
+  fmt.Fprintf(w, "type %s struct {\n", name)
+  for i := range columns {
+    column_type, path := go_type(table, &columns[i])
+    if path != "" { imports[path] = true }
+    fmt.Fprintf(w, "%s %s `db:%q`\n", go_name(columns[i].Name), column_type, columns[i].Name)
+  }
+  fmt.Fprintf(w, "}\n\n")
+
+  fmt.Fprintf(w, "// TableName returns the name of the table of %s.\n", name)
+  fmt.Fprintf(w, "func (%s) TableName() string { return %q }\n\n", name, table)
+
+  fmt.Fprintf(w, "// Names of the table %s and its columns.\n", table)
+  fmt.Fprintf(w, "const (\n%sTable = %q\n", name, table)
+  for _, column := range columns {
+    fmt.Fprintf(w, "%sColumn%s = %q\n", name, go_name(column.Name), column.Name)
+  }
+  fmt.Fprintf(w, ")\n")
+}
+
+// Returns the Go type of a column of a table, and the import path it needs 
+// or "".
+func go_type(table string, column *Column) (string, string) {
+  column_type := strings.ToLower(column.ColumnType)
+  unsigned    := column.IsUnsigned()
+  name, path  := "string", ""
+  switch column.DataType {
+  case "tinyint":
+    switch {
+    case strings.HasPrefix(column_type, "tinyint(1)"): name = "bool"
+    case unsigned:                                     name = "uint8"
+    default:                                           name = "int8"
+    }
+  case "smallint", "year":
+    name = "int16"
+    if unsigned { name = "uint16" }
+  case "mediumint", "int", "integer":
+    name = "int32"
+    if unsigned { name = "uint32" }
+  case "bigint":
+    name = "int64"
+    if unsigned { name = "uint64" }
+  case "float":
+    name = "float32"
+  case "double", "real":
+    name = "float64"
+  case "date", "datetime", "timestamp":
+    name, path = "time.Time", "time"
+  // Named like the types of `write_enum(...)`
+  case "enum":
+    name = go_name(table) + go_name(column.Name)
+  // NULL is a nil slice
+  case "json":
+    return "json.RawMessage", "encoding/json"
+  case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit":
+    return "[]byte", ""
+  }
+  if column.Nullable { name = "*" + name }
+  return name, path
+}
+
+// Converts a SQL name like "user_id" to an exported Go name like "UserID".
+func go_name(name string) string {
+  words := strings.FieldsFunc(name, func(r rune) bool {
+    return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+  })
+
+  var result strings.Builder
+  for _, word := range words {
+    if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+      result.WriteString(initialism)
+      continue
+    }
+    runes := []rune(word)
+    runes[0] = unicode.ToUpper(runes[0])
+    result.WriteString(string(runes))
+  }
+
+  if result.Len() == 0 { return "Empty" }
+  if unicode.IsDigit([]rune(result.String())[0]) {
+    return "V" + result.String()
+  }
+  return result.String()
+}
@@ -0,0 +1,46 @@
+package mysql_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestGenerateEnumColumns(t *testing.T) {
+  mock := mysqltest.New(t)
+  mock.On("`COLUMNS`").Rows(
+    []string{"COLUMN_NAME", "ORDINAL_POSITION", "DATA_TYPE", "COLUMN_TYPE", "IS_NULLABLE",
+      "COLUMN_DEFAULT", "COLUMN_KEY", "EXTRA", "LENGTH", "PRECISION", "SCALE"},
+    []interface{}{"id", 1, "int", "int", "NO", nil, "PRI", "", 0, 10, 0},
+    []interface{}{"status", 2, "enum", "enum('pending','paid')", "NO", nil, "", "", 7, 0, 0},
+    []interface{}{"refund", 3, "enum", "enum('full','partial')", "YES", nil, "", "", 7, 0, 0},
+  )
+
+  tests := []struct {
+    name     string
+    generate func(*bytes.Buffer) error
+    want     []string
+  }{
+    {
+      "models", func(src *bytes.Buffer) error { return mysql.GenerateModels(src, "models", "orders") },
+      []string{"Status OrdersStatus ", "Refund *OrdersRefund "},
+    },
+    {
+      "enums", func(src *bytes.Buffer) error { return mysql.GenerateEnums(src, "models", "orders") },
+      []string{"case nil:\n\t\t*v = \"\"\n\t\treturn nil", "if v == \"\" {\n\t\t\treturn nil, nil"},
+    },
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      var src bytes.Buffer
+      if err := test.generate(&src); err != nil { t.Fatal(err) }
+      for _, want := range test.want {
+        if !strings.Contains(src.String(), want) { t.Errorf("source doesn't contain %q:\n%s", want, src.String()) }
+      }
+    })
+  }
+}
@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	m "github.com/go-sql-driver/mysql"
+)
+
+// Returned by queries when `QueryLimiter` has no token left within its 
+// `MaxWait`.
+var ErrRateLimited = errors.New("mysql: query rate limit exceeded")
+
+// Returned by queries while `QueryBreaker` is open.
+var ErrCircuitOpen = errors.New("mysql: circuit breaker is open")
+
+// Optional token bucket limiting the rate of every query of this package, to 
+// protect a degraded server from stampedes. Set it before the first query.
+//
+// Example:
+//   mysql.QueryLimiter = &mysql.RateLimiter{Rate: 500, Burst: 100, MaxWait: 50 * time.Millisecond}
+var QueryLimiter *RateLimiter
+
+// Optional circuit breaker failing queries fast with `ErrCircuitOpen` after 
+// consecutive connection errors, instead of waiting for the timeouts of an 
+// unreachable server. Set it before the first query.
+//
+// Example:
+//   mysql.QueryBreaker = &mysql.CircuitBreaker{Threshold: 5, Cooldown: 10 * time.Second}
+var QueryBreaker *CircuitBreaker
+
+// Token bucket of `QueryLimiter`.
+type RateLimiter struct {
+  // Tokens added per second
+  Rate float64
+  // Maximum number of tokens, the size of a burst of queries. `Rate` rounded 
+  // up when less than 1
+  Burst int
+  // Maximum time a query waits for a token, 0 fails queries right away with 
+  // `ErrRateLimited`
+  MaxWait time.Duration
+
+  mu      sync.Mutex
+  tokens  float64
+  updated time.Time
+}
+
+// Takes a token, waiting up to `MaxWait` or the end of `ctx`.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+  delay := l.reserve()
+  if delay < 0 { return ErrRateLimited }
+  if delay == 0 { return nil }
+  timer := time.NewTimer(delay)
+  defer timer.Stop()
+  select {
+  case <-timer.C:
+    return nil
+  case <-ctx.Done():
+    l.cancel()
+    return ctx.Err()
+  }
+}
+
+// Takes a token, and returns how long to wait until it's available. A token 
+// which would be available after `MaxWait` isn't taken, and -1 is returned.
+func (l *RateLimiter) reserve() time.Duration {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  burst := float64(l.Burst)
+  if burst < 1 { burst = float64(int(l.Rate + 0.999)) }
+  if burst < 1 { burst = 1 }
+
+  now := time.Now()
+  if l.updated.IsZero() {
+    l.tokens = burst
+  } else {
+    l.tokens += now.Sub(l.updated).Seconds() * l.Rate
+    if l.tokens > burst { l.tokens = burst }
+  }
+  l.updated = now
+
+  if l.tokens >= 1 {
+    l.tokens--
+    return 0
+  }
+  if l.Rate <= 0 { return -1 }
+  delay := time.Duration((1 - l.tokens) / l.Rate * float64(time.Second))
+  if delay > l.MaxWait { return -1 }
+  l.tokens--
+  return delay
+}
+
+// Returns the token of a query which stopped waiting.
+func (l *RateLimiter) cancel() {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  l.tokens++
+}
+
+// States of a `CircuitBreaker`.
+const (
+  CircuitClosed   = "closed"
+  CircuitOpen     = "open"
+  CircuitHalfOpen = "half-open"
+)
+
+// Circuit breaker of `QueryBreaker`. It opens after `Threshold` consecutive 
+// connection errors, and fails queries while it's open. After `Cooldown` it's 
+// half-open: one query at a time probes the server, and the circuit closes 
+// when a probe succeeds or opens again when it fails.
+type CircuitBreaker struct {
+  // Consecutive connection errors opening the circuit, 5 when 0
+  Threshold int
+  // Time the circuit stays open before probing, 10s when 0
+  Cooldown time.Duration
+  // Called when the state changes, e.g. to log or alert
+  OnStateChange func(from, to string)
+
+  mu       sync.Mutex
+  state    string
+  failures int
+  opened   time.Time
+  probing  bool
+  // State changes to pass to `OnStateChange` once unlocked
+  changes  [][2]string
+}
+
+// Returns the current state, `CircuitClosed`, `CircuitOpen` or 
+// `CircuitHalfOpen`.
+func (b *CircuitBreaker) State() string {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  if b.state == CircuitOpen && time.Since(b.opened) >= b.cooldown() {
+    return CircuitHalfOpen
+  }
+  if b.state == "" { return CircuitClosed }
+  return b.state
+}
+
+// Returns `ErrCircuitOpen` when a query can't run, and whether the query is 
+// the probe of a half-open circuit.
+func (b *CircuitBreaker) allow() (bool, error) {
+  b.mu.Lock()
+  defer b.unlock()
+  switch b.state {
+  case CircuitOpen:
+    if time.Since(b.opened) < b.cooldown() { return false, ErrCircuitOpen }
+    b.set_state(CircuitHalfOpen)
+  case CircuitHalfOpen:
+  default:
+    return false, nil
+  }
+  if b.probing { return false, ErrCircuitOpen }
+  b.probing = true
+  return true, nil
+}
+
+// Records the result of a query.
+func (b *CircuitBreaker) record(probe bool, err error) {
+  b.mu.Lock()
+  defer b.unlock()
+  if probe { b.probing = false }
+  if !is_connection_error(err) {
+    b.failures = 0
+    if probe { b.set_state(CircuitClosed) }
+    return
+  }
+
+  b.failures++
+  threshold := b.Threshold
+  if threshold < 1 { threshold = 5 }
+  if probe || b.failures >= threshold && b.state != CircuitOpen {
+    b.opened = time.Now()
+    b.set_state(CircuitOpen)
+  }
+}
+
+func (b *CircuitBreaker) set_state(state string) {
+  from := b.state
+  if from == "" { from = CircuitClosed }
+  b.state = state
+  if from != state && b.OnStateChange != nil {
+    b.changes = append(b.changes, [2]string{from, state})
+  }
+}
+
+// Unlocks the breaker and calls `OnStateChange` with the state changes.
+func (b *CircuitBreaker) unlock() {
+  changes := b.changes
+  b.changes = nil
+  b.mu.Unlock()
+  for _, change := range changes { b.OnStateChange(change[0], change[1]) }
+}
+
+func (b *CircuitBreaker) cooldown() time.Duration {
+  if b.Cooldown <= 0 { return 10 * time.Second }
+  return b.Cooldown
+}
+
+// Server errors of an unavailable server: too many connections, too many 
+// connections of the user, server shutdown.
+var unavailable_codes = map[uint16]bool{1040: true, 1203: true, 1053: true}
+
+// Reports whether `err` means the server can't be reached or is overloaded, 
+// as opposed to an error of the query.
+func is_connection_error(err error) bool {
+  if err == nil { return false }
+  var net_err net.Error
+  var mysql_err *m.MySQLError
+  switch {
+  case errors.Is(err, driver.ErrBadConn), errors.Is(err, m.ErrInvalidConn),
+    errors.As(err, &net_err):
+    return true
+  case errors.As(err, &mysql_err):
+    return unavailable_codes[mysql_err.Number]
+  }
+  return false
+}
+
+// Waits for `QueryLimiter` and checks `QueryBreaker` before a query. The 
+// returned function records the result of the query.
+func (d *DB) guard() (func(err error), error) {
+  if limiter := QueryLimiter; limiter != nil {
+    if err := limiter.Wait(d.context()); err != nil { return nil, err }
+  }
+  breaker := QueryBreaker
+  if breaker == nil { return func(error) {}, nil }
+  probe, err := breaker.allow()
+  if err != nil { return nil, err }
+  return func(err error) { breaker.record(probe, err) }, nil
+}
@@ -0,0 +1,36 @@
+package mysql_test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestBreakerProbeWithPanickingHook(t *testing.T) {
+  mysql.OnBeforeQuery(func(query string, args []any) {
+    if strings.Contains(query, "hook_panic") { panic("hook failed") }
+  })
+  breaker := &mysql.CircuitBreaker{Threshold: 1, Cooldown: time.Millisecond}
+  mysql.QueryBreaker = breaker
+  defer func() { mysql.QueryBreaker = nil }()
+
+  mock := mysqltest.New(t)
+  mock.On("lost").Error(driver.ErrBadConn)
+  exec := func(query string) (err interface{}) {
+    defer func() { err = recover() }()
+    mysql.Exec(query)
+    return nil
+  }
+
+  exec("SELECT 'lost';")
+  if state := breaker.State(); state != mysql.CircuitOpen { t.Fatalf("state = %s, want %s", state, mysql.CircuitOpen) }
+  time.Sleep(2 * time.Millisecond)
+
+  if err := exec("SELECT 'hook_panic';"); err != "hook failed" { t.Fatalf("hook panic = %v", err) }
+  if err := exec("SELECT 1;"); err != nil { t.Fatalf("query after the panicking hook failed: %v", err) }
+  if state := breaker.State(); state != mysql.CircuitClosed { t.Fatalf("state = %s, want %s", state, mysql.CircuitClosed) }
+}
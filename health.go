@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Query run by `HealthCheck(...)` after pinging the server, e.g. "SELECT 1" to 
+// check that queries are executed. When empty, the server is only pinged.
+var HealthCheckQuery = ""
+
+// Maximum duration of a health check run by `HealthHandler()`.
+var HealthCheckTimeout = 2 * time.Second
+
+// Pings the server, and runs `HealthCheckQuery` when it's set. A nil error 
+// means the service can reach its database.
+func HealthCheck(ctx context.Context) error {
+  pool := db.Load()
+  if pool == nil { return errors.New("mysql: not initialized") }
+
+  if err := pool.PingContext(ctx); err != nil { return err }
+  if HealthCheckQuery == "" { return nil }
+
+  var result interface{}
+  return pool.QueryRowContext(ctx, HealthCheckQuery).Scan(&result)
+}
+
+// Returns an `http.Handler` which responds 200 when `HealthCheck(...)` passes 
+// within `HealthCheckTimeout`, otherwise 503, to be used as a readiness probe.
+//
+// Example:
+//   http.Handle("/ready", mysql.HealthHandler())
+func HealthHandler() http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    ctx, cancel := context.WithTimeout(r.Context(), HealthCheckTimeout)
+    defer cancel()
+
+    if err := HealthCheck(ctx); err != nil {
+      http.Error(w, err.Error(), http.StatusServiceUnavailable)
+      return
+    }
+    w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    w.Write([]byte("ok\n"))
+  })
+}
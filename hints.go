@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options of index hints, in the order they're written.
+var index_hint_options = []struct{ option, clause string }{
+  {"use_index", "USE INDEX"},
+  {"force_index", "FORCE INDEX"},
+  {"ignore_index", "IGNORE INDEX"},
+}
+
+// Returns the index hints of the "use_index", "force_index" and 
+// "ignore_index" options, e.g. " FORCE INDEX (`idx_user_created`)", or "". 
+// An empty list of "use_index" is `USE INDEX ()`, which uses no index.
+func index_hints(options map[string]interface{}) string {
+  var hints strings.Builder
+  for _, hint := range index_hint_options {
+    value, ok := options[hint.option]
+    if !ok { continue }
+    indexes := hint_list(hint.option, value)
+    escaped := make([]string, len(indexes))
+    for i, index := range indexes { escaped[i] = EscapeId(index, true) }
+    fmt.Fprintf(&hints, " %s (%s)", hint.clause, strings.Join(escaped, ", "))
+  }
+  return hints.String()
+}
+
+// Returns the optimizer hint comment of the "hints" option followed by a 
+// space, e.g. "/*+ MAX_EXECUTION_TIME(1000) */ ", or "".
+func optimizer_hints(options map[string]interface{}) string {
+  value, ok := options["hints"]
+  if !ok { return "" }
+  var hints []string
+  for _, hint := range hint_list("hints", value) {
+    hint = strings.TrimSpace(hint)
+    hint = strings.TrimSuffix(strings.TrimPrefix(hint, "/*+"), "*/")
+    if strings.Contains(hint, "*/") {
+      panic(fmt.Errorf("mysql: invalid optimizer hint %q", hint))
+    }
+    if hint = strings.TrimSpace(hint); hint != "" { hints = append(hints, hint) }
+  }
+  if len(hints) == 0 { return "" }
+  return "/*+ " + strings.Join(hints, " ") + " */ "
+}
+
+func hint_list(option string, value interface{}) []string {
+  switch value := value.(type) {
+  case string:
+    if value == "" { return nil }
+    return []string{value}
+  case []string: return value
+  }
+  panic(fmt.Errorf("mysql: invalid %s option type %T", option, value))
+}
@@ -0,0 +1,134 @@
+package mysql
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Callbacks around writes of a table, registered by `RegisterHooks(...)`. 
+// Before hooks may modify `data` and fail the write by returning an error, 
+// which panics. The results of after hooks of `InsertManyResilient(...)` are 
+// the results of multi-row statements. A soft delete runs the delete hooks, 
+// not the update hooks.
+type TableHooks struct {
+  BeforeInsert func(data map[string]interface{}) error
+  AfterInsert  func(data map[string]interface{}, result sql.Result)
+  BeforeUpdate func(data, where map[string]interface{}) error
+  AfterUpdate  func(data, where map[string]interface{}, result sql.Result)
+  BeforeDelete func(where map[string]interface{}) error
+  AfterDelete  func(where map[string]interface{}, result sql.Result)
+}
+
+var hooks struct {
+  sync.RWMutex
+  before []func(query string, args []any)
+  after  []func(query string, args []any, duration time.Duration, err error)
+  tables map[string][]TableHooks
+}
+
+// Registers a callback called before every query is sent to the server, 
+// e.g. for auditing. It's called before `QueryLimiter` and `QueryBreaker` as 
+// well, so also for queries they fail.
+func OnBeforeQuery(fn func(query string, args []any)) {
+  hooks.Lock()
+  defer hooks.Unlock()
+  hooks.before = append(hooks.before, fn)
+}
+
+// Registers a callback called after every query, e.g. for metrics. For 
+// queries returning rows, it's called once the server responded, before the 
+// rows are read.
+//
+// Example:
+//   mysql.OnAfterQuery(func(query string, args []any, duration time.Duration, err error) {
+//     query_duration.WithLabelValues(mysql.Fingerprint(query)).Observe(duration.Seconds())
+//   })
+func OnAfterQuery(fn func(query string, args []any, duration time.Duration, err error)) {
+  hooks.Lock()
+  defer hooks.Unlock()
+  hooks.after = append(hooks.after, fn)
+}
+
+// Registers callbacks around inserts, updates and deletes of a table. Nil 
+// callbacks are skipped, and hooks registered several times run in order.
+//
+// Example:
+//   mysql.RegisterHooks("users", mysql.TableHooks{
+//     BeforeInsert: func(data map[string]interface{}) error {
+//       if data["email"] == nil { return errors.New("users: email is required") }
+//       return nil
+//     },
+//     AfterDelete: func(where map[string]interface{}, result sql.Result) {
+//       audit.Log("users deleted", where)
+//     },
+//   })
+func RegisterHooks(table string, table_hooks TableHooks) {
+  hooks.Lock()
+  defer hooks.Unlock()
+  if hooks.tables == nil { hooks.tables = map[string][]TableHooks{} }
+  hooks.tables[table] = append(hooks.tables[table], table_hooks)
+}
+
+func before_query(query string, args []any) {
+  hooks.RLock()
+  callbacks := hooks.before
+  hooks.RUnlock()
+  for _, fn := range callbacks { fn(query, args) }
+}
+
+func after_query(query string, args []any, start time.Time, err error) {
+  record_query(query, start, err)
+
+  hooks.RLock()
+  callbacks := hooks.after
+  hooks.RUnlock()
+  if len(callbacks) == 0 { return }
+  duration := time.Since(start)
+  for _, fn := range callbacks { fn(query, args, duration, err) }
+}
+
+func table_hooks(table string) []TableHooks {
+  hooks.RLock()
+  defer hooks.RUnlock()
+  return hooks.tables[table]
+}
+
+func before_insert(table string, data map[string]interface{}) {
+  for _, h := range table_hooks(table) {
+    if h.BeforeInsert == nil { continue }
+    if err := h.BeforeInsert(data); err != nil { panic(err) }
+  }
+}
+
+func after_insert(table string, data map[string]interface{}, result sql.Result) {
+  for _, h := range table_hooks(table) {
+    if h.AfterInsert != nil { h.AfterInsert(data, result) }
+  }
+}
+
+func before_update(table string, data, where map[string]interface{}) {
+  for _, h := range table_hooks(table) {
+    if h.BeforeUpdate == nil { continue }
+    if err := h.BeforeUpdate(data, where); err != nil { panic(err) }
+  }
+}
+
+func after_update(table string, data, where map[string]interface{}, result sql.Result) {
+  for _, h := range table_hooks(table) {
+    if h.AfterUpdate != nil { h.AfterUpdate(data, where, result) }
+  }
+}
+
+func before_delete(table string, where map[string]interface{}) {
+  for _, h := range table_hooks(table) {
+    if h.BeforeDelete == nil { continue }
+    if err := h.BeforeDelete(where); err != nil { panic(err) }
+  }
+}
+
+func after_delete(table string, where map[string]interface{}, result sql.Result) {
+  for _, h := range table_hooks(table) {
+    if h.AfterDelete != nil { h.AfterDelete(where, result) }
+  }
+}
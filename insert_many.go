@@ -0,0 +1,207 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	m "github.com/go-sql-driver/mysql"
+)
+
+// Number of rows inserted per statement by `InsertManyResilient(...)`, unless 
+// the option "chunk_size" is given.
+const DefaultInsertChunkSize = 500
+
+// Report of `InsertManyResilient(...)`.
+type InsertReport struct {
+  // Affected rows of every statement, rows updated by ON DUPLICATE KEY UPDATE 
+  // count twice
+  RowsAffected int64
+  // Rows which failed, ordered by their index
+  Failed []InsertFailure
+}
+
+// Row which couldn't be inserted.
+type InsertFailure struct {
+  // Index of the row in the given rows
+  Index int
+  Row   map[string]interface{}
+  Err   error
+}
+
+// Errors caused by the data of a row, like duplicate keys, foreign key and 
+// NOT NULL violations or invalid values.
+var row_error_codes = map[uint16]bool{
+  1048: true, 1062: true, 1264: true, 1292: true, 1364: true, 1366: true,
+  1406: true, 1451: true, 1452: true, 1586: true, 3819: true,
+}
+
+// Inserts rows in chunks with multi-row INSERT statements, for import 
+// pipelines with dirty input data. When a chunk fails because of the data of 
+// a row, its rows are inserted one by one to find the failing rows, which are 
+// reported. Missing columns of a row are set to their DEFAULT. Before insert 
+// hooks run once per row, retried rows aren't passed to them again.
+//
+// Parameters:
+//   - `table`: The name of the table
+//   - `rows`: rows to insert
+//   - `options`: Optional map of options:
+//     - `chunk_size`: int, rows per statement, `DefaultInsertChunkSize` by 
+//                     default
+//     - `continue`: bool, continue after rows failed, otherwise stop at the 
+//                   first failing chunk
+//     - `on_duplicate`: "ignore" to skip duplicate rows with INSERT IGNORE, 
+//                       or "update" to update them with ON DUPLICATE KEY 
+//                       UPDATE
+// Returns:
+//   - *InsertReport: affected rows and failed rows, also when an error is 
+//                    returned
+//   - error: error which isn't caused by a row, like a lost connection, or 
+//            the first row error when "continue" isn't set
+//
+// Example:
+//   report, err := mysql.InsertManyResilient("products", rows, _json{"continue": true})
+//   if err != nil { return err }
+//   for _, failure := range report.Failed {
+//     log.Printf("row %d: %v", failure.Index, failure.Err)
+//   }
+func (d *DB) InsertManyResilient(
+  table string,
+  rows []map[string]interface{},
+  args ...map[string]interface{},
+) (*InsertReport, error) {
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+  chunk_size, ok := options["chunk_size"].(int)
+  if !ok || chunk_size < 1 { chunk_size = DefaultInsertChunkSize }
+  keep_going, _ := options["continue"].(bool)
+
+  statement, suffix := "INSERT INTO", ""
+  switch options["on_duplicate"] {
+  case nil:
+  case "ignore":
+    statement = "INSERT IGNORE INTO"
+  case "update":
+    suffix = "update"
+  default:
+    panic(fmt.Errorf("mysql: invalid on_duplicate option %v", options["on_duplicate"]))
+  }
+
+  report := &InsertReport{}
+  for start := 0; start < len(rows); start += chunk_size {
+    end := start + chunk_size
+    if end > len(rows) { end = len(rows) }
+
+    // Models and before hooks apply once per row, also when it's retried
+    data, err := insert_data(table, rows[start:end])
+    if err != nil { return report, err }
+
+    n, err := d.insert_rows(statement, table, data, suffix)
+    if err == nil {
+      report.RowsAffected += n
+      continue
+    }
+    if !is_row_error(err) { return report, err }
+
+    // Find the failing rows of the chunk, every row may succeed when the 
+    // conflicting row was changed meanwhile
+    failed := len(report.Failed)
+    for i := start; i < end; i++ {
+      n, err := d.insert_rows(statement, table, data[i-start:i-start+1], suffix)
+      if err == nil {
+        report.RowsAffected += n
+        continue
+      }
+      if !is_row_error(err) { return report, err }
+      report.Failed = append(report.Failed, InsertFailure{i, rows[i], err})
+    }
+    if !keep_going && len(report.Failed) > failed {
+      return report, report.Failed[failed].Err
+    }
+  }
+  return report, nil
+}
+
+// Returns the rows with their model applied and their before hooks run.
+func insert_data(
+  table string,
+  rows []map[string]interface{},
+) (data []map[string]interface{}, err error) {
+  defer RecoverError(&err)
+  data = make([]map[string]interface{}, len(rows))
+  for i, row := range rows {
+    data[i] = model_data(table, row, true)
+    before_insert(table, data[i])
+  }
+  return data, nil
+}
+
+// Inserts rows of `insert_data(...)` with one statement and returns the 
+// affected rows.
+func (d *DB) insert_rows(
+  statement, table string,
+  data []map[string]interface{},
+  on_duplicate string,
+) (affected int64, err error) {
+  defer RecoverError(&err)
+
+  seen := map[string]bool{}
+  var columns []string
+  for _, row := range data {
+    for column := range row {
+      if !seen[column] {
+        seen[column] = true
+        columns = append(columns, column)
+      }
+    }
+  }
+  sort.Strings(columns)
+
+  var values []interface{}
+  tuples := make([]string, len(data))
+  for i, row := range data {
+    placeholders := make([]string, len(columns))
+    for j, column := range columns {
+      value, ok := row[column]
+      if !ok {
+        placeholders[j] = "DEFAULT"
+      } else if expr, ok := value.(*Expression); ok {
+        placeholders[j] = expr.query
+        values = append(values, expr.values...)
+      } else {
+        placeholders[j] = "?"
+        values = append(values, value)
+      }
+    }
+    tuples[i] = "(" + strings.Join(placeholders, ", ") + ")"
+  }
+
+  escaped := make([]string, len(columns))
+  for i, column := range columns { escaped[i] = EscapeId(column) }
+  query := fmt.Sprintf(
+    "%s %s(%s) VALUES%s",
+    statement, EscapeId(d.tenant_table(table)), strings.Join(escaped, ", "),
+    strings.Join(tuples, ", "),
+  )
+  if on_duplicate == "update" {
+    var created_at string
+    if model := ModelOf(table); model != nil { created_at = model.CreatedAt }
+
+    var updates []string
+    for i, column := range escaped {
+      if columns[i] == created_at { continue }
+      updates = append(updates, column + " = VALUES(" + column + ")")
+    }
+    query += " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+  }
+
+  result := d.Exec(query+";", values...)
+  for _, row := range data { after_insert(table, row, result) }
+  return result.RowsAffected()
+}
+
+func is_row_error(err error) bool {
+  var mysql_err *m.MySQLError
+  return errors.As(err, &mysql_err) && row_error_codes[mysql_err.Number]
+}
@@ -0,0 +1,58 @@
+package mysql_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	m "github.com/go-sql-driver/mysql"
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestInsertManyResilientFallback(t *testing.T) {
+  var inserted int
+  mysql.RegisterHooks("resilient_products", mysql.TableHooks{
+    BeforeInsert: func(map[string]interface{}) error {
+      inserted++
+      return nil
+    },
+  })
+  duplicate := &m.MySQLError{Number: 1062, Message: "Duplicate entry"}
+  rows := []map[string]interface{}{{"id": 1}, {"id": 2, "sku": "a"}, {"id": 3}}
+
+  tests := []struct {
+    name     string
+    fail_row bool
+    options  map[string]interface{}
+    failed   []int
+    affected int64
+    err      error
+  }{
+    {"every retried row succeeds", false, nil, nil, 3, nil},
+    {"retried row fails", true, nil, []int{1}, 2, duplicate},
+    {"retried row fails and continues", true, map[string]interface{}{"continue": true}, []int{1}, 2, nil},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      mock := mysqltest.New(t)
+      inserted = 0
+      // Only the multi-row statement and the single row with a sku fail
+      mock.On("), (").Error(duplicate)
+      if test.fail_row { mock.On("`sku`) VALUES(?, ?);").Error(duplicate) }
+      mock.On("INSERT").Result(0, 1)
+
+      report, err := mysql.InsertManyResilient("resilient_products", rows, test.options)
+      if !errors.Is(err, test.err) { t.Fatalf("err = %v, want %v", err, test.err) }
+      var failed []int
+      for _, failure := range report.Failed { failed = append(failed, failure.Index) }
+      if !reflect.DeepEqual(failed, test.failed) { t.Errorf("failed rows = %v, want %v", failed, test.failed) }
+      if report.RowsAffected != test.affected {
+        t.Errorf("affected rows = %d, want %d", report.RowsAffected, test.affected)
+      }
+      if got := len(mock.Queries()); got != 4 { t.Errorf("queries = %d, want 4", got) }
+      if inserted != len(rows) { t.Errorf("before insert hooks ran %d times, want %d", inserted, len(rows)) }
+    })
+  }
+}
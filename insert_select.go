@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Copies rows of a table into another one with `INSERT INTO ... SELECT ...`, 
+// without reading them into the application. Useful for archiving and table 
+// copy jobs.
+//
+// Parameters:
+//   - `dest_table`: The name of the table to insert into
+//   - `columns`: columns of `dest_table` to fill, in the order of the 
+//                selected columns
+//   - `src_table`: The name of the table to select from
+//   - `where`: conditions of the rows to copy, same format as `Select(...)`
+//   - `options`: Optional map, the options of `Select(...)` for the source 
+//                query. The selected columns default to `columns`.
+// Options:
+//   - `ignore`: bool, use `INSERT IGNORE` to skip duplicate rows
+//
+// Returns:
+//   - sql.Result: Result of the statement execution
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // INSERT INTO `orders_archive` (`id`, `user_id`, `total`) 
+//   //   SELECT `id`, `user_id`, `total` FROM `orders` WHERE `created_at` < ?
+//   columns := []string{"id", "user_id", "total"}
+//   mysql.InsertFromSelect("orders_archive", columns, "orders", _json{
+//     "created_at <": cutoff,
+//   })
+func (d *DB) InsertFromSelect(
+  dest_table string,
+  columns []string,
+  src_table string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) sql.Result {
+  if len(columns) == 0 {
+    panic(fmt.Errorf("mysql: insert into %q from select needs columns", dest_table))
+  }
+
+  options := map[string]interface{}{}
+  if len(args) > 0 {
+    for key, value := range args[0] { options[key] = value }
+  }
+  _, has_column  := options["column"]
+  _, has_columns := options["columns"]
+  if !has_column && !has_columns { options["columns"] = columns }
+
+  escaped := make([]string, len(columns))
+  for i, column := range columns { escaped[i] = EscapeId(column) }
+
+  statement := "INSERT INTO"
+  if ignore, _ := options["ignore"].(bool); ignore { statement = "INSERT IGNORE INTO" }
+
+  cols := prepare_columns(options)
+  options = d.select_options(options)
+  source, values := build_select(cols, src_table, where, options, true)
+  dest := route_table(dest_table, option_tenant(options))
+  query := fmt.Sprintf(
+    "%s %s (%s) %s;",
+    statement, escape_table(dest), strings.Join(escaped, ", "), source,
+  )
+  return d.Exec(query, values...)
+}
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JOIN clause for the `"join"` option of `Select(...)`.
+type Join struct {
+  // "INNER" (default), "LEFT", "RIGHT" or "CROSS"
+  Type  string
+  // Name of the joined table, optionally followed by an alias, e.g. 
+  // "profiles p" or "profiles AS p"
+  Table string
+  // Conditions of the ON clause in the same format as where maps. Use 
+  // `Ident(...)` to compare against another column instead of a value.
+  On    map[string]interface{}
+}
+
+// Creates an escaped identifier expression, so a column can be used as a 
+// value in where maps and ON conditions, e.g. `{"p.user_id": mysql.Ident("u.id")}`.
+func Ident(name string) *Expression {
+  return Raw(EscapeId(name))
+}
+
+func join_query(options map[string]interface{}) (string, []interface{}) {
+  var joins []Join
+  switch value := options["join"].(type) {
+  case Join:   joins = []Join{value}
+  case []Join: joins = value
+  case nil:    return "", nil
+  default:
+    panic(fmt.Errorf("mysql: invalid join option type %T", value))
+  }
+
+  var query  strings.Builder
+  var values []interface{}
+  for _, join := range joins {
+    kind := strings.ToUpper(strings.TrimSpace(join.Type))
+    switch kind {
+    case "": kind = "INNER"
+    case "INNER", "LEFT", "RIGHT", "CROSS":
+    default:
+      panic(fmt.Errorf("mysql: unsupported join type %q", join.Type))
+    }
+
+    query.WriteString(" " + kind + " JOIN " + escape_tenant_table(join.Table, option_tenant(options)))
+    if len(join.On) > 0 {
+      on, args := prepare_conditions(join.On)
+      query.WriteString(" ON " + on)
+      values = append(values, args...)
+    }
+  }
+  return query.String(), values
+}
+
+func escape_table(table string) string {
+  parts := strings.Fields(table)
+  if len(parts) == 3 && strings.ToUpper(parts[1]) == "AS" {
+    parts = []string{parts[0], parts[2]}
+  }
+  switch len(parts) {
+  case 1: return EscapeId(parts[0])
+  case 2: return EscapeId(parts[0]) + " AS " + EscapeId(parts[1])
+  }
+  panic(fmt.Errorf("mysql: invalid table name %q", table))
+}
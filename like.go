@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+var like_escaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Escapes the LIKE wildcards `%`, `_` and the escape character `\` in user 
+// input, so it only matches literally in a LIKE pattern.
+//
+// Example:
+//   mysql.EscapeLike("100%_done") // output: 100\%\_done
+func EscapeLike(s string) string {
+  return like_escaper.Replace(s)
+}
+
+// Returns a where map matching rows where `column` starts with `prefix`. The 
+// prefix is escaped, so user input can't inject wildcards. The generated 
+// `column LIKE 'prefix%'` pattern can use an index on the column.
+//
+// Example:
+//   // SELECT * FROM `users` WHERE `name` LIKE ? -- "jo%"
+//   where := mysql.StartsWith("name", query)
+//   where["active"] = 1
+//   mysql.Select("users", where)
+func StartsWith(column, prefix string) map[string]interface{} {
+  return map[string]interface{}{
+    column + " LIKE": EscapeLike(prefix) + "%",
+  }
+}
+
+// Returns a where map matching rows where `column` contains `s`. The input is 
+// escaped, so user input can't inject wildcards.
+//
+// A pattern starting with `%` can't use an index and scans every row, prefer 
+// `StartsWith(...)` or a FULLTEXT index for large tables.
+func Contains(column, s string) map[string]interface{} {
+  return map[string]interface{}{
+    column + " LIKE": "%" + EscapeLike(s) + "%",
+  }
+}
+
+// Returns a where map matching rows where `column` ends with `suffix`. The 
+// input is escaped, so user input can't inject wildcards. Like 
+// `Contains(...)`, the pattern can't use an index.
+func EndsWith(column, suffix string) map[string]interface{} {
+  return map[string]interface{}{
+    column + " LIKE": "%" + EscapeLike(suffix),
+  }
+}
+
+// Position of the input in a LIKE pattern built by `Like(...)`.
+type LikeMode int
+
+const (
+  // Matches values equal to the input, without wildcards
+  LikeExact LikeMode = iota
+  // Matches values starting with the input, can use an index
+  LikePrefix
+  // Matches values ending with the input
+  LikeSuffix
+  // Matches values containing the input
+  LikeContains
+)
+
+type like struct {
+  pattern string
+}
+
+// Returns a where map value matching `input` with LIKE at the position of 
+// `mode`. The input is escaped, so user input can't inject wildcards. Using 
+// the key operator `!=` or "NOT LIKE" negates the condition.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // SELECT * FROM `products` WHERE `name` LIKE ? AND `sku` NOT LIKE ?
+//   //   -- "%50\% off%", "TEST-%"
+//   mysql.Select("products", _json{
+//     "name":         mysql.Like("50% off", mysql.LikeContains),
+//     "sku NOT LIKE":  mysql.Like("TEST-", mysql.LikePrefix),
+//   })
+func Like(input string, mode LikeMode) interface{} {
+  pattern := EscapeLike(input)
+  switch mode {
+  case LikeExact:
+  case LikePrefix:   pattern = pattern + "%"
+  case LikeSuffix:   pattern = "%" + pattern
+  case LikeContains: pattern = "%" + pattern + "%"
+  default:
+    panic(fmt.Errorf("mysql: invalid LIKE mode %d", mode))
+  }
+  return like{pattern}
+}
+
+func (l like) condition(column, operator string) (string, []interface{}) {
+  values := []interface{}{l.pattern}
+  switch operator {
+  case "", "=", "LIKE":        return column + " LIKE ?", values
+  case "!=", "<>", "NOT LIKE":  return column + " NOT LIKE ?", values
+  }
+  panic(fmt.Errorf("mysql: operator %q can't be used with Like", operator))
+}
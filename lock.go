@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Returned by `AcquireLock(...)` when the lock is held by another session 
+// until the timeout.
+var ErrLockTimeout = errors.New("mysql: timeout acquiring lock")
+
+// Acquires a named lock with `GET_LOCK(...)`, a mutex across every instance 
+// of a service using the same database, e.g. for jobs which must run once. 
+// The lock belongs to a connection which is taken from the pool until 
+// `release` is called, so always call it. When the connection is lost the 
+// server releases the lock.
+//
+// Parameters:
+//   - `name`: name of the lock, at most 64 characters and shared by every 
+//             database of the server, so prefix it with the application
+//   - `timeout`: time to wait for the lock, rounded up to seconds. A negative 
+//                timeout waits forever
+// Returns:
+//   - func(): releases the lock and returns the connection, it's safe to call 
+//             more than once
+//   - error: `ErrLockTimeout` or the error of the query
+//
+// Example:
+//   release, err := mysql.AcquireLock("billing:invoice-run", 10 * time.Second)
+//   if err != nil { return err }
+//   defer release()
+func (d *DB) AcquireLock(name string, timeout time.Duration) (release func(), err error) {
+  seconds := int64(-1)
+  if timeout >= 0 { seconds = int64(math.Ceil(timeout.Seconds())) }
+
+  handle := d
+  var conn *sql.Conn
+  if d.tx == nil && d.conn == nil {
+    conn, err = d.pool.Load().Conn(d.context())
+    if err != nil { return nil, err }
+    handle = &DB{pool: d.pool, conn: conn, ctx: d.ctx, analytic: d.analytic}
+  }
+  close_conn := func() {
+    if conn != nil { conn.Close() }
+  }
+
+  // NULL when the lock can't be acquired for another reason, like a kill
+  var acquired sql.NullInt64
+  err = handle.scan_row([]interface{}{&acquired}, "SELECT GET_LOCK(?, ?);", name, seconds)
+  if err == nil && acquired.Int64 != 1 { err = ErrLockTimeout }
+  if err != nil {
+    close_conn()
+    return nil, err
+  }
+
+  var once sync.Once
+  return func() {
+    once.Do(func() {
+      // The caller's context is often cancelled by the time of a deferred 
+      // release, which must not keep the lock
+      releasing    := *handle
+      releasing.ctx = context.WithoutCancel(handle.context())
+
+      var released sql.NullInt64
+      query := "SELECT RELEASE_LOCK(?);"
+      err := releasing.scan_row([]interface{}{&released}, query, name)
+      if err == nil || conn == nil {
+        if err != nil { Logger.Println(err) }
+        close_conn()
+        return
+      }
+
+      // The session may still hold the lock, so the connection is discarded 
+      // instead of returned to the pool
+      Logger.Println(err)
+      conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+      conn.Close()
+    })
+  }, nil
+}
@@ -0,0 +1,26 @@
+package mysql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestReleaseLockAfterCancel(t *testing.T) {
+  mock := mysqltest.New(t)
+  mock.On("GET_LOCK").Rows([]string{"acquired"}, []interface{}{1})
+  mock.On("RELEASE_LOCK").Rows([]string{"released"}, []interface{}{1})
+
+  ctx, cancel := context.WithCancel(context.Background())
+  release, err := mysql.WithContext(ctx).AcquireLock("jobs:report", time.Second)
+  if err != nil { t.Fatal(err) }
+  cancel()
+  release()
+
+  want := []string{"SELECT GET_LOCK(?, ?);", "SELECT RELEASE_LOCK(?);"}
+  if got := mock.SQL(); !reflect.DeepEqual(got, want) { t.Fatalf("queries = %q, want %q", got, want) }
+}
@@ -0,0 +1,123 @@
+// Prometheus metrics of package `github.com/je3f0o/go-jeefo-mysql`. 
+//
+// Example:
+//   mysql.Init(cfg)
+//   if err := metrics.Register(prometheus.DefaultRegisterer); err != nil {
+//     log.Fatal(err)
+//   }
+//   http.Handle("/metrics", promhttp.Handler())
+package metrics
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	m "github.com/go-sql-driver/mysql"
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+  queries = prometheus.NewCounterVec(prometheus.CounterOpts{
+    Name: "mysql_queries_total",
+    Help: "Executed queries by operation and table.",
+  }, []string{"operation", "table"})
+
+  query_errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+    Name: "mysql_query_errors_total",
+    Help: "Failed queries by MySQL error code, \"other\" for errors of the client.",
+  }, []string{"code"})
+
+  query_duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+    Name:    "mysql_query_duration_seconds",
+    Help:    "Duration of queries until the server responded, by operation.",
+    Buckets: prometheus.ExponentialBuckets(0.0005, 2, 16),
+  }, []string{"operation"})
+
+  hook sync.Once
+)
+
+var (
+  operation_pattern = regexp.MustCompile(`^\s*(?:/\*.*?\*/\s*)*([A-Za-z]+)`)
+  table_pattern     = regexp.MustCompile("(?i)\\b(?:FROM|INTO|UPDATE|JOIN)\\s+(`[^`]+`(?:\\.`[^`]+`)?|[A-Za-z0-9_$.]+)")
+)
+
+// Registers the query metrics and the connection pool statistics of the 
+// default connection on `registerer`. Queries are counted once this was 
+// called.
+func Register(registerer prometheus.Registerer) error {
+  hook.Do(func() { mysql.OnAfterQuery(observe) })
+
+  for _, collector := range []prometheus.Collector{
+    queries, query_errors, query_duration, pool_collector{},
+  } {
+    if err := registerer.Register(collector); err != nil {
+      var registered prometheus.AlreadyRegisteredError
+      if !errors.As(err, &registered) { return err }
+    }
+  }
+  return nil
+}
+
+func observe(query string, args []any, duration time.Duration, err error) {
+  operation, table := classify(query)
+  queries.WithLabelValues(operation, table).Inc()
+  query_duration.WithLabelValues(operation).Observe(duration.Seconds())
+  if err == nil { return }
+
+  code := "other"
+  var mysql_err *m.MySQLError
+  if errors.As(err, &mysql_err) { code = strconv.Itoa(int(mysql_err.Number)) }
+  query_errors.WithLabelValues(code).Inc()
+}
+
+// Returns the lower case operation and the first table of a query.
+func classify(query string) (string, string) {
+  operation := ""
+  if match := operation_pattern.FindStringSubmatch(query); match != nil {
+    operation = strings.ToLower(match[1])
+  }
+  table := ""
+  if match := table_pattern.FindStringSubmatch(query); match != nil {
+    table = strings.ReplaceAll(match[1], "`", "")
+  }
+  return operation, table
+}
+
+// Collects `sql.DBStats` of the default connection when scraped.
+type pool_collector struct{}
+
+var (
+  pool_open     = prometheus.NewDesc("mysql_pool_open_connections", "Established connections, in use and idle.", nil, nil)
+  pool_in_use   = prometheus.NewDesc("mysql_pool_in_use_connections", "Connections currently in use.", nil, nil)
+  pool_idle     = prometheus.NewDesc("mysql_pool_idle_connections", "Idle connections.", nil, nil)
+  pool_max_open = prometheus.NewDesc("mysql_pool_max_open_connections", "Maximum number of open connections, 0 is unlimited.", nil, nil)
+  pool_waits    = prometheus.NewDesc("mysql_pool_wait_total", "Waits for a free connection.", nil, nil)
+  pool_wait     = prometheus.NewDesc("mysql_pool_wait_seconds_total", "Time blocked waiting for a free connection.", nil, nil)
+  pool_closed   = prometheus.NewDesc("mysql_pool_closed_total", "Connections closed by reason.", []string{"reason"}, nil)
+)
+
+func (pool_collector) Describe(descs chan<- *prometheus.Desc) {
+  for _, desc := range []*prometheus.Desc{
+    pool_open, pool_in_use, pool_idle, pool_max_open, pool_waits, pool_wait, pool_closed,
+  } {
+    descs <- desc
+  }
+}
+
+func (pool_collector) Collect(metrics chan<- prometheus.Metric) {
+  stats := mysql.Stats()
+  metrics <- prometheus.MustNewConstMetric(pool_open, prometheus.GaugeValue, float64(stats.OpenConnections))
+  metrics <- prometheus.MustNewConstMetric(pool_in_use, prometheus.GaugeValue, float64(stats.InUse))
+  metrics <- prometheus.MustNewConstMetric(pool_idle, prometheus.GaugeValue, float64(stats.Idle))
+  metrics <- prometheus.MustNewConstMetric(pool_max_open, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+  metrics <- prometheus.MustNewConstMetric(pool_waits, prometheus.CounterValue, float64(stats.WaitCount))
+  metrics <- prometheus.MustNewConstMetric(pool_wait, prometheus.CounterValue, stats.WaitDuration.Seconds())
+  metrics <- prometheus.MustNewConstMetric(pool_closed, prometheus.CounterValue, float64(stats.MaxIdleClosed), "max_idle")
+  metrics <- prometheus.MustNewConstMetric(pool_closed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), "max_idle_time")
+  metrics <- prometheus.MustNewConstMetric(pool_closed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
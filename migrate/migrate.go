@@ -0,0 +1,307 @@
+// Schema migrations on the connection of package 
+// `github.com/je3f0o/go-jeefo-mysql`. Migrations are Go functions or SQL files, 
+// applied versions are tracked in the `schema_migrations` table.
+//
+// Example:
+//   //go:embed migrations/*.sql
+//   var files embed.FS
+//
+//   mysql.Init(cfg)
+//   migrations := migrate.New()
+//   sub, _ := fs.Sub(files, "migrations")
+//   if err := migrations.RegisterFS(sub); err != nil { log.Fatal(err) }
+//   migrations.Register(3, "backfill_slugs", backfill_slugs, nil)
+//   if err := migrations.Up(ctx); err != nil { log.Fatal(err) }
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+// Function applying or reverting a migration. Queries of handles created by 
+// `mysql.WithContext(ctx)` run in the transaction of the migration.
+type Func func(ctx context.Context) error
+
+// Registered migration.
+type Migration struct {
+  Version int64
+  Name    string
+  Up      Func
+  // Nil when the migration can't be reverted
+  Down    Func
+}
+
+// Set of migrations, applied in the order of their versions.
+type Migrate struct {
+  // Table tracking applied versions, "schema_migrations" by default
+  Table      string
+  migrations map[int64]*Migration
+}
+
+// Creates an empty set of migrations.
+func New() *Migrate {
+  return &Migrate{Table: "schema_migrations", migrations: map[int64]*Migration{}}
+}
+
+// Registers a migration written in Go. It panics when the version is already 
+// registered.
+//
+// Example:
+//   migrations.Register(3, "backfill_slugs", func(ctx context.Context) error {
+//     mysql.WithContext(ctx).Exec("UPDATE posts SET slug = LOWER(title);")
+//     return nil
+//   }, nil)
+func (m *Migrate) Register(version int64, name string, up, down Func) {
+  if _, ok := m.migrations[version]; ok {
+    panic(fmt.Errorf("migrate: version %d is already registered", version))
+  }
+  m.migrations[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+}
+
+// Registers SQL files of a file system, usually an `embed.FS`. Files are 
+// named like "0001_create_users.up.sql" and "0001_create_users.down.sql", 
+// statements of a file are separated by semicolons, or by the delimiter of a 
+// `DELIMITER` line like in the mysql client:
+//   DELIMITER $$
+//   CREATE TRIGGER orders_total BEFORE INSERT ON orders FOR EACH ROW
+//   BEGIN
+//     SET NEW.total = NEW.price * NEW.quantity;
+//   END$$
+//   DELIMITER ;
+func (m *Migrate) RegisterFS(fsys fs.FS) error {
+  names, err := fs.Glob(fsys, "*.sql")
+  if err != nil { return err }
+
+  for _, file := range names {
+    base      := strings.TrimSuffix(path.Base(file), ".sql")
+    direction := path.Ext(base)
+    if direction != ".up" && direction != ".down" { continue }
+    base = strings.TrimSuffix(base, direction)
+
+    prefix, name, _ := strings.Cut(base, "_")
+    version, err := strconv.ParseInt(prefix, 10, 64)
+    if err != nil {
+      return fmt.Errorf("migrate: invalid version of file %q", file)
+    }
+    content, err := fs.ReadFile(fsys, file)
+    if err != nil { return err }
+
+    migration, ok := m.migrations[version]
+    if !ok {
+      migration = &Migration{Version: version, Name: name}
+      m.migrations[version] = migration
+    }
+    fn, err := sql_func(string(content))
+    if err != nil { return fmt.Errorf("migrate: %s: %w", file, err) }
+    if direction == ".up" {
+      migration.Up = fn
+    } else {
+      migration.Down = fn
+    }
+  }
+  return nil
+}
+
+// Returns registered migrations ordered by version.
+func (m *Migrate) Migrations() []*Migration {
+  migrations := make([]*Migration, 0, len(m.migrations))
+  for _, migration := range m.migrations {
+    migrations = append(migrations, migration)
+  }
+  sort.Slice(migrations, func(i, j int) bool {
+    return migrations[i].Version < migrations[j].Version
+  })
+  return migrations
+}
+
+// Returns applied versions in ascending order.
+func (m *Migrate) Applied(ctx context.Context) (versions []int64, err error) {
+  defer mysql.RecoverError(&err)
+  m.ensure_table(ctx)
+
+  query := fmt.Sprintf("SELECT `version` FROM %s ORDER BY `version`;", mysql.EscapeId(m.Table))
+  rows  := mysql.WithContext(ctx).ExecQuery(query)
+  defer rows.Close()
+  for rows.Next() {
+    var version int64
+    if err := rows.Scan(&version); err != nil { return nil, err }
+    versions = append(versions, version)
+  }
+  return versions, rows.Err()
+}
+
+// Applies every pending migration.
+func (m *Migrate) Up(ctx context.Context) error {
+  return m.To(ctx, -1)
+}
+
+// Reverts the latest applied migration.
+func (m *Migrate) Down(ctx context.Context) error {
+  applied, err := m.Applied(ctx)
+  if err != nil { return err }
+  if len(applied) == 0 { return nil }
+
+  target := int64(0)
+  if len(applied) > 1 { target = applied[len(applied)-2] }
+  return m.To(ctx, target)
+}
+
+// Applies pending migrations up to `version`, and reverts applied migrations 
+// newer than `version`. A negative version applies every migration. Each 
+// migration runs in its own transaction, but note that MySQL commits DDL 
+// statements implicitly.
+func (m *Migrate) To(ctx context.Context, version int64) error {
+  applied, err := m.Applied(ctx)
+  if err != nil { return err }
+  is_applied := map[int64]bool{}
+  for _, v := range applied { is_applied[v] = true }
+
+  migrations := m.Migrations()
+  for _, migration := range migrations {
+    if is_applied[migration.Version] { continue }
+    if version >= 0 && migration.Version > version { break }
+    if err := m.run(ctx, migration, true); err != nil { return err }
+  }
+
+  for i := len(applied) - 1; i >= 0 && version >= 0 && applied[i] > version; i-- {
+    migration, ok := m.migrations[applied[i]]
+    if !ok {
+      return fmt.Errorf("migrate: applied version %d is not registered", applied[i])
+    }
+    if err := m.run(ctx, migration, false); err != nil { return err }
+  }
+  return nil
+}
+
+func (m *Migrate) run(ctx context.Context, migration *Migration, up bool) (err error) {
+  fn := migration.Up
+  if !up { fn = migration.Down }
+  if fn == nil {
+    return fmt.Errorf("migrate: version %d %q can't be reverted", migration.Version, migration.Name)
+  }
+
+  defer func() {
+    if err != nil {
+      err = fmt.Errorf("migrate: version %d %q: %w", migration.Version, migration.Name, err)
+    }
+  }()
+  defer mysql.RecoverError(&err)
+
+  return mysql.Transaction(ctx, func(ctx context.Context) error {
+    if err := fn(ctx); err != nil { return err }
+    handle := mysql.WithContext(ctx)
+    if up {
+      handle.Insert(m.Table, map[string]interface{}{
+        "version": migration.Version,
+        "name":    migration.Name,
+      })
+    } else {
+      handle.Delete(m.Table, map[string]interface{}{"version": migration.Version})
+    }
+    return nil
+  })
+}
+
+func (m *Migrate) ensure_table(ctx context.Context) {
+  mysql.WithContext(ctx).Exec(fmt.Sprintf(
+    "CREATE TABLE IF NOT EXISTS %s (" +
+      "`version` BIGINT NOT NULL PRIMARY KEY, " +
+      "`name` VARCHAR(255) NOT NULL, " +
+      "`applied_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP" +
+    ");",
+    mysql.EscapeId(m.Table),
+  ))
+}
+
+// Returns a migration function executing the statements of a SQL file.
+func sql_func(content string) (Func, error) {
+  statements, err := split_statements(content)
+  if err != nil { return nil, err }
+  return func(ctx context.Context) error {
+    handle := mysql.WithContext(ctx)
+    for _, statement := range statements { handle.Exec(statement) }
+    return nil
+  }, nil
+}
+
+// Splits SQL on delimiters outside of quotes and comments. Like in the mysql 
+// client, a `DELIMITER` line changes the delimiter, for statements with 
+// semicolons like bodies of triggers and procedures.
+func split_statements(content string) ([]string, error) {
+  var statements []string
+  var current strings.Builder
+  var quote byte
+  delimiter := ";"
+
+  flush := func() {
+    if statement := strings.TrimSpace(current.String()); statement != "" {
+      statements = append(statements, statement + ";")
+    }
+    current.Reset()
+  }
+
+  for i := 0; i < len(content); i++ {
+    ch := content[i]
+    switch {
+    case quote != 0:
+      current.WriteByte(ch)
+      if ch == '\\' && i+1 < len(content) {
+        i++
+        current.WriteByte(content[i])
+      } else if ch == quote {
+        quote = 0
+      }
+    case ch == '\'' || ch == '"' || ch == '`':
+      quote = ch
+      current.WriteByte(ch)
+    case ch == '-' && strings.HasPrefix(content[i:], "-- "), ch == '#':
+      // Skip line comments
+      for i < len(content) && content[i] != '\n' { i++ }
+      current.WriteByte('\n')
+    case strings.HasPrefix(content[i:], "/*"):
+      end := strings.Index(content[i+2:], "*/")
+      if end < 0 { end = len(content) - i - 2 }
+      comment := content[i:min(i+end+4, len(content))]
+      i += len(comment) - 1
+      // Executable comments and optimizer hints are kept
+      if strings.HasPrefix(comment, "/*!") || strings.HasPrefix(comment, "/*+") {
+        current.WriteString(comment)
+      } else {
+        current.WriteByte(' ')
+      }
+    case is_line_start(content, i) && has_prefix_fold(content[i:], "DELIMITER "):
+      flush()
+      end := strings.IndexByte(content[i:], '\n')
+      if end < 0 { end = len(content) - i }
+      delimiter = strings.TrimSpace(content[i+len("DELIMITER ") : i+end])
+      if delimiter == "" { return nil, errors.New("empty DELIMITER") }
+      i += end
+    case strings.HasPrefix(content[i:], delimiter):
+      flush()
+      i += len(delimiter) - 1
+    default:
+      current.WriteByte(ch)
+    }
+  }
+  flush()
+  return statements, nil
+}
+
+// Reports whether only spaces precede position `i` on its line.
+func is_line_start(content string, i int) bool {
+  for i > 0 && (content[i-1] == ' ' || content[i-1] == '\t') { i-- }
+  return i == 0 || content[i-1] == '\n'
+}
+
+func has_prefix_fold(s, prefix string) bool {
+  return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+  tests := []struct {
+    name       string
+    content    string
+    statements []string
+  }{
+    {
+      "semicolons", "CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);\n",
+      []string{"CREATE TABLE a (id INT);", "INSERT INTO a VALUES (1);"},
+    },
+    {
+      "quotes", "INSERT INTO a VALUES ('x;y', \"it\\\"s;\", 'it\\'s;');",
+      []string{"INSERT INTO a VALUES ('x;y', \"it\\\"s;\", 'it\\'s;');"},
+    },
+    {
+      "line comments", "-- first; comment\nSELECT 1; # second; comment\nSELECT 2;",
+      []string{"SELECT 1;", "SELECT 2;"},
+    },
+    {
+      "block comments", "/* a; b */ SELECT 1; SELECT /* c; */ 2;",
+      []string{"SELECT 1;", "SELECT   2;"},
+    },
+    {
+      "executable comments", "/*!40101 SET NAMES utf8mb4 */; SELECT /*+ MAX_EXECUTION_TIME(1) */ 1;",
+      []string{"/*!40101 SET NAMES utf8mb4 */;", "SELECT /*+ MAX_EXECUTION_TIME(1) */ 1;"},
+    },
+    {
+      "delimiter",
+      "DELIMITER $$\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW\nBEGIN\n  SET NEW.x = 1;\nEND$$\n" +
+        "delimiter ;\nSELECT 1;",
+      []string{
+        "CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW\nBEGIN\n  SET NEW.x = 1;\nEND;",
+        "SELECT 1;",
+      },
+    },
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      statements, err := split_statements(test.content)
+      if err != nil { t.Fatal(err) }
+      if !reflect.DeepEqual(statements, test.statements) {
+        t.Fatalf("statements = %q, want %q", statements, test.statements)
+      }
+    })
+  }
+
+  if _, err := split_statements("DELIMITER \nSELECT 1;"); err == nil { t.Fatal("expected an error of an empty delimiter") }
+}
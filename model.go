@@ -0,0 +1,274 @@
+package mysql
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Declarative definition of a table's conventions, consumed by the CRUD 
+// functions of this package once registered with `RegisterModel(...)`.
+type Model struct {
+  Table string
+  // Primary key column, "id" when empty
+  PrimaryKey string
+  // Column holding the deletion time. When set `Delete(...)` updates it to 
+  // `NOW()` instead of deleting rows, and reads, updates and aggregates skip 
+  // deleted rows. Pass the option `"with_deleted": true` to include them, or 
+  // `"force": true` to `Delete(...)` to really delete rows.
+  SoftDelete string
+  // Column set to `NOW()` on insert
+  CreatedAt string
+  // Column set to `NOW()` on insert and update
+  UpdatedAt string
+  // Default column values applied on insert, see `RegisterDefaults(...)`
+  Defaults map[string]interface{}
+  // Columns encrypted with AES-GCM before they are written and decrypted when 
+  // they are read, see `SetEncryptionKey(...)`. Encrypted columns should be 
+  // VARBINARY or BLOB, and can't be used in where conditions.
+  Encrypted []string
+  // Tables referencing this table
+  Relations []Relation
+  // Integer column of optimistic locking, incremented by every update. When 
+  // the data of `Update(...)` holds the version read with the row, the row 
+  // is only updated when its version is still the same, otherwise the update 
+  // panics with `ErrStaleRow`. The column needs a default, e.g. 
+  // `version INT NOT NULL DEFAULT 0`.
+  Version string
+  // BINARY(16) columns holding UUIDs. String values of data maps and of where 
+  // conditions on the column are written as their 16 bytes, and values of 
+  // result rows are read as strings in the canonical form, see `UUID`.
+  UUID []string
+}
+
+// Relation of a model to a dependent table.
+type Relation struct {
+  // Dependent table
+  Table string
+  // Column of the dependent table referencing this model
+  ForeignKey string
+  // Referenced column of this model, the primary key when empty
+  LocalKey string
+}
+
+var models struct {
+  sync.RWMutex
+  tables map[string]*Model
+}
+
+var encryption atomic.Value // cipher.AEAD
+
+// Registers the conventions of a table. Registering a table again replaces 
+// its model.
+//
+// Example:
+//   mysql.RegisterModel(mysql.Model{
+//     Table:      "users",
+//     SoftDelete: "deleted_at",
+//     CreatedAt:  "created_at",
+//     UpdatedAt:  "updated_at",
+//     Defaults:   map[string]interface{}{"status": "pending"},
+//     Encrypted:  []string{"phone"},
+//     Relations:  []mysql.Relation{{Table: "orders", ForeignKey: "user_id"}},
+//     Version:    "version",
+//     UUID:       []string{"public_id"},
+//   })
+func RegisterModel(model Model) {
+  if model.Table == "" { panic(errors.New("mysql: model without table name")) }
+  if model.PrimaryKey == "" { model.PrimaryKey = "id" }
+
+  models.Lock()
+  defer models.Unlock()
+  if models.tables == nil { models.tables = map[string]*Model{} }
+  models.tables[model.Table] = &model
+}
+
+// Returns the registered model of a table, or nil.
+func ModelOf(table string) *Model {
+  name, _ := split_alias(table)
+  models.RLock()
+  defer models.RUnlock()
+  return models.tables[name]
+}
+
+// Sets the key used for encrypted model columns, 16, 24 or 32 bytes to select 
+// AES-128, AES-192 or AES-256.
+func SetEncryptionKey(key []byte) {
+  block, err := aes.NewCipher(key)
+  if err != nil { panic(err) }
+  aead, err := cipher.NewGCM(block)
+  if err != nil { panic(err) }
+  encryption.Store(aead)
+}
+
+// Returns the where map extended with the soft-delete condition of the table, 
+// and with UUID strings converted to bytes.
+func model_where(
+  table string,
+  where, options map[string]interface{},
+) map[string]interface{} {
+  model := ModelOf(table)
+  if model == nil { return where }
+  where = uuid_where(model, where)
+  if model.SoftDelete == "" { return where }
+  if with_deleted, _ := options["with_deleted"].(bool); with_deleted {
+    return where
+  }
+
+  column := model.SoftDelete
+  if _, alias := split_alias(table); alias != "" {
+    column = alias + "." + column
+  }
+  result := make(map[string]interface{}, len(where)+1)
+  for key, value := range where { result[key] = value }
+  result[column] = nil
+  return result
+}
+
+// Returns a copy of `data` with defaults, timestamps and encryption of the 
+// table applied, or `data` itself when the table has no model.
+func model_data(
+  table string,
+  data map[string]interface{},
+  inserting bool,
+) map[string]interface{} {
+  model := ModelOf(table)
+  if model == nil { return data }
+
+  result := make(map[string]interface{}, len(data)+len(model.Defaults)+2)
+  for column, value := range data { result[column] = value }
+
+  if inserting {
+    for column, value := range model.Defaults {
+      if _, ok := result[column]; ok { continue }
+      if generate, ok := value.(func() interface{}); ok {
+        value = generate()
+      }
+      result[column] = value
+    }
+    set_timestamp(result, model.CreatedAt)
+  }
+  set_timestamp(result, model.UpdatedAt)
+
+  for _, column := range model.UUID {
+    if value, ok := result[column]; ok { result[column] = uuid_value(value) }
+  }
+  for _, column := range model.Encrypted {
+    if value, ok := result[column]; ok && value != nil {
+      result[column] = encrypt(value)
+    }
+  }
+  return result
+}
+
+// Returns a copy of `where` with UUID strings of conditions on the UUID 
+// columns of the model converted to bytes, or `where` itself when none is.
+func uuid_where(model *Model, where map[string]interface{}) map[string]interface{} {
+  if len(model.UUID) == 0 { return where }
+  var result map[string]interface{}
+  for key, value := range where {
+    fields := strings.Fields(key)
+    if len(fields) == 0 || !contains_string(model.UUID, fields[0]) { continue }
+    if result == nil {
+      result = make(map[string]interface{}, len(where))
+      for key, value := range where { result[key] = value }
+    }
+    result[key] = uuid_value(value)
+  }
+  if result == nil { return where }
+  return result
+}
+
+// Returns the data and where maps of an update with optimistic locking: the 
+// version column is incremented, and an expected version in `data` is moved 
+// to the conditions. The bool reports whether an expected version was given.
+func model_version(
+  table string,
+  data, where map[string]interface{},
+) (map[string]interface{}, map[string]interface{}, bool) {
+  model := ModelOf(table)
+  if model == nil || model.Version == "" { return data, where, false }
+  expected, ok := data[model.Version]
+  if _, raw := expected.(*Expression); raw { return data, where, false }
+
+  column := EscapeId(model.Version)
+  result := make(map[string]interface{}, len(data))
+  for key, value := range data { result[key] = value }
+  result[model.Version] = Raw(column + " + 1")
+  if !ok { return result, where, false }
+
+  conditions := make(map[string]interface{}, len(where)+1)
+  for key, value := range where { conditions[key] = value }
+  conditions[model.Version] = expected
+  return result, conditions, true
+}
+
+// Decrypts the encrypted columns of a result row in place.
+func model_row(table string, row map[string]interface{}) {
+  model := ModelOf(table)
+  if model == nil { return }
+  for _, column := range model.UUID {
+    if value, ok := row[column]; ok { row[column] = uuid_text(value) }
+  }
+  for _, column := range model.Encrypted {
+    switch value := row[column].(type) {
+    case string:
+      if value != "" { row[column] = decrypt(value) }
+    case []byte:
+      if len(value) > 0 { row[column] = decrypt(string(value)) }
+    }
+  }
+}
+
+func set_timestamp(data map[string]interface{}, column string) {
+  if column == "" { return }
+  if _, ok := data[column]; !ok { data[column] = Raw("NOW()") }
+}
+
+func split_alias(table string) (string, string) {
+  parts := strings.Fields(table)
+  switch len(parts) {
+  case 0:    return "", ""
+  case 1:    return parts[0], ""
+  }
+  return parts[0], parts[len(parts)-1]
+}
+
+func encryption_cipher() cipher.AEAD {
+  aead, _ := encryption.Load().(cipher.AEAD)
+  if aead == nil {
+    panic(errors.New("mysql: encryption key is not set, see SetEncryptionKey"))
+  }
+  return aead
+}
+
+func encrypt(value interface{}) []byte {
+  aead := encryption_cipher()
+  var plaintext []byte
+  switch v := value.(type) {
+  case []byte: plaintext = v
+  case string: plaintext = []byte(v)
+  default:     plaintext = []byte(fmt.Sprint(v))
+  }
+
+  nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+  if _, err := rand.Read(nonce); err != nil { panic(err) }
+  return aead.Seal(nonce, nonce, plaintext, nil)
+}
+
+func decrypt(value string) string {
+  aead := encryption_cipher()
+  data := []byte(value)
+  if len(data) < aead.NonceSize() {
+    panic(errors.New("mysql: encrypted value is too short"))
+  }
+  nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+  plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+  if err != nil { panic(err) }
+  return string(plaintext)
+}
@@ -0,0 +1,68 @@
+package mysql
+
+import "database/sql"
+
+// Result sets of a query returning more than one, like a stored procedure or 
+// several statements with `Config.MultiStatements` enabled. Created by 
+// `QueryMulti(...)`, it must be closed.
+type ResultSets struct {
+  rows    *sql.Rows
+  started bool
+}
+
+// Executes a query returning several result sets, which are read one at a 
+// time like `Select(...)` rows. Result sets without columns, e.g. the status 
+// of a procedure call, are skipped.
+//
+// Example:
+//   sets := mysql.QueryMulti("CALL dashboard(?);", user_id)
+//   defer sets.Close()
+//   for sets.Next() {
+//     rows := sets.Rows()
+//     // code...
+//   }
+func (d *DB) QueryMulti(query string, values ...interface{}) *ResultSets {
+  return &ResultSets{rows: d.ExecQuery(query, values...)}
+}
+
+// Advances to the next result set, it returns false when there is none left.
+func (r *ResultSets) Next() bool {
+  for {
+    if r.started && !r.rows.NextResultSet() {
+      if err := r.rows.Err(); err != nil { panic(err) }
+      return false
+    }
+    r.started = true
+
+    columns, err := r.rows.Columns()
+    if err != nil { panic(err) }
+    if len(columns) > 0 { return true }
+  }
+}
+
+// Returns the rows of the current result set, values are converted like the 
+// package defaults of `Select(...)`, see `TypedValues`.
+func (r *ResultSets) Rows() []map[string]interface{} {
+  rows := scan_maps(r.rows, nil)
+  if rows == nil { rows = []map[string]interface{}{} }
+  return rows
+}
+
+// Calls `fn` for every row of the current result set until it returns an 
+// error, which is returned.
+func (r *ResultSets) Each(fn func(row map[string]interface{}) error) error {
+  return each_map(r.rows, nil, fn)
+}
+
+// Reads every remaining result set and closes them.
+func (r *ResultSets) All() [][]map[string]interface{} {
+  defer r.Close()
+  var sets [][]map[string]interface{}
+  for r.Next() { sets = append(sets, r.Rows()) }
+  return sets
+}
+
+// Closes the result sets.
+func (r *ResultSets) Close() error {
+  return r.rows.Close()
+}
@@ -8,12 +8,17 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"reflect"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	m "github.com/go-sql-driver/mysql"
 )
@@ -25,6 +30,98 @@ type Config struct {
   DBName   string `yaml:"name"`
   Username string `yaml:"user"`
   Password string `yaml:"pass"`
+  // Source of short lived passwords like IAM auth tokens, used instead of 
+  // `Password` by new connections, see `RDSIAMAuth` and `CloudSQLIAMAuth`.
+  Credentials CredentialProvider `yaml:"-"`
+  // When `Host` is "localhost" and `Socket` is empty, look for a Unix socket 
+  // at the standard paths and prefer it over TCP, like the mysql CLI does.
+  AutoSocket bool `yaml:"auto_socket,omitempty"`
+  // Failover hosts like "replica-1" or "replica-1:3307", tried in order when 
+  // `Host` can't be connected, see `FailbackInterval`. The port defaults to 
+  // `Port`.
+  Hosts []string `yaml:"hosts,omitempty"`
+  // Connection pool settings, zero values keep the `database/sql` defaults.
+  MaxOpenConns    int           `yaml:"max_open_conns,omitempty"`
+  MaxIdleConns    int           `yaml:"max_idle_conns,omitempty"`
+  ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime,omitempty"`
+  ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time,omitempty"`
+  // Driver settings, see github.com/go-sql-driver/mysql#parameters
+  //   - `ParseTime`: scan DATE and DATETIME values into `time.Time`, which 
+  //                  `Select(...)` returns with the "typed" option. 
+  //                  `time.Time` values of data and where maps are always 
+  //                  sent in `Location`
+  //   - `Location`: time zone of `time.Time` values, e.g. "Local" or 
+  //                 "Europe/Berlin", UTC when empty
+  //   - `Charset`: connection character set, "utf8mb4" when empty. Set it to 
+  //                "utf8" to keep the old 3-byte behavior
+  //   - `Collation`: connection collation, "utf8mb4_unicode_ci" when empty 
+  //                  and the character set is utf8mb4
+  //   - `Timeout`, `ReadTimeout`, `WriteTimeout`: dial and I/O timeouts
+  //   - `ClientFoundRows`: report matched rows as affected rows of UPDATE 
+  //                        statements, instead of changed rows only
+  //   - `Params`: any other DSN parameter, unknown parameters are set as 
+  //               session variables
+  ParseTime       bool              `yaml:"parse_time,omitempty"`
+  Location        string            `yaml:"loc,omitempty"`
+  Charset         string            `yaml:"charset,omitempty"`
+  Collation       string            `yaml:"collation,omitempty"`
+  Timeout         time.Duration     `yaml:"timeout,omitempty"`
+  ReadTimeout     time.Duration     `yaml:"read_timeout,omitempty"`
+  WriteTimeout    time.Duration     `yaml:"write_timeout,omitempty"`
+  ClientFoundRows bool              `yaml:"client_found_rows,omitempty"`
+  Params          map[string]string `yaml:"params,omitempty"`
+  // Protocol settings, for large bulk inserts and WAN connections:
+  //   - `Compress`: zlib compression of the traffic, which saves bandwidth on 
+  //                 slow links for some CPU on both sides
+  //   - `MaxAllowedPacket`: largest packet in bytes the driver sends, keep it 
+  //                         at most the `max_allowed_packet` of the server. 
+  //                         0 keeps the driver default of 64MiB, -1 reads it 
+  //                         from the server when connecting
+  //   - `InterpolateParams`: interpolate values into the query instead of 
+  //                          preparing every statement, which saves a round 
+  //                          trip per query
+  //   - `MultiStatements`: allow several statements in one query, see 
+  //                        `QueryMulti(...)`. Interpolated values can't break 
+  //                        out of their statement, but raw SQL built from 
+  //                        user input can run any statement
+  Compress          bool `yaml:"compress,omitempty"`
+  MaxAllowedPacket  int  `yaml:"max_allowed_packet,omitempty"`
+  InterpolateParams bool `yaml:"interpolate_params,omitempty"`
+  MultiStatements   bool `yaml:"multi_statements,omitempty"`
+  // Session variables set on every new connection, e.g. "sql_mode", 
+  // "time_zone", "wait_timeout" or "group_concat_max_len". Strings holding 
+  // an integer are set as integers.
+  Session map[string]interface{} `yaml:"session,omitempty"`
+  // Connection attributes shown in `performance_schema.session_connect_attrs`, 
+  // so DBAs can tell which service owns a connection. The attribute 
+  // "program_name" is `ProgramName`, or the executable name when empty, and 
+  // "host" is the host name of the machine, e.g. the pod name.
+  ProgramName          string            `yaml:"program_name,omitempty"`
+  ConnectionAttributes map[string]string `yaml:"connection_attributes,omitempty"`
+  // Tunes the connection for reporting workloads, so they don't need the 
+  // settings of OLTP connections, see `Connect(...)`:
+  //   - longer server timeouts for slowly consumed results, `net_read_timeout` 
+  //     and `net_write_timeout` are one hour unless given in `Params`
+  //   - parameters are interpolated instead of preparing every statement
+  //   - `Select(...)` needs a "limit", use the streaming `SelectEach(...)` or 
+  //     `SelectInBatches(...)` for large results
+  //   - SELECT queries get the SQL_BIG_RESULT hint
+  Analytic bool `yaml:"analytic,omitempty"`
+  // Encrypted connection settings, nil for a plain connection.
+  TLS *TLSConfig `yaml:"tls,omitempty"`
+  // SSH bastion the connections go through, nil to connect directly.
+  SSH *SSHConfig `yaml:"ssh,omitempty"`
+  // Per-environment overrides, see `Config.Active()`.
+  Profile  string             `yaml:"profile,omitempty"`
+  Profiles map[string]*Config `yaml:"profiles,omitempty"`
+  // Set on the result of `WithProfile(...)`, which is already active
+  resolved bool
+}
+
+// Value of a where map which builds its own condition for the escaped column 
+// and the operator given in the key.
+type condition interface {
+  condition(column, operator string) (string, []interface{})
 }
 
 type _where struct {
@@ -32,11 +129,29 @@ type _where struct {
   values []interface{}
 }
 
-var db *sql.DB
+var db atomic.Pointer[sql.DB]
+
+// Standard Unix socket paths checked when `Config.AutoSocket` is enabled.
+var socket_paths = []string{
+  "/var/run/mysqld/mysqld.sock",
+  "/var/lib/mysql/mysql.sock",
+  "/tmp/mysql.sock",
+}
 
-// Set to `true` will be logging every query with values before executing.
+// Set to `true` will be logging every query with values before executing. 
+// Values of sensitive columns are masked, see `RedactColumns`.
 var Debug = false
 
+// Logger of every message of this package, replace it to send messages to the 
+// logger of the application.
+var Logger = log.Default()
+
+// An empty slice in a where map, which would be the syntax error `IN()`, 
+// matches no row with IN (`1 = 0`) and every row with NOT IN (`1 = 1`). Set it 
+// to `true` to panic with `ErrEmptyIn` instead, to catch callers which forgot 
+// to check for an empty list.
+var EmptyInError = false
+
 // Returns a pointer to a newly allocated `Config` struct with default values
 // for:
 //   - `Host` "127.0.0.1"
@@ -58,6 +173,7 @@ func NewConfig() *Config {
 //   - string : the escaped identifier
 // Example:
 //   EscapeId("INFORMATION_SCHEMA.COLUMNS")       // output: `INFORMATION_SCHEMA`.`COLUMNS`
+//   EscapeId("u.*")                              // output: `u`.*
 //   EscapeId("some.weird.table.or.column", true) // output: `some.weird.table.or.column`
 func EscapeId(id string, ignore_dot ...bool) string {
   if len(ignore_dot) > 0 && ignore_dot[0] {
@@ -66,44 +182,148 @@ func EscapeId(id string, ignore_dot ...bool) string {
 
   parts := strings.Split(id, ".")
   for i, part := range parts {
+    if part == "*" && i == len(parts)-1 { continue }
     parts[i] = "`" + strings.Replace(part, "`", "``", -1) + "`"
   }
   return strings.Join(parts, ".")
 }
 
-// Initialize database connection with given configuration.
+// Initialize database connection with given configuration. The active profile 
+// is applied first, see `Config.Active()`. When `Debug` is enabled the server 
+// version and main settings are logged, see `ServerInfo()`.
 func Init(cfg *Config) {
-  var target string
+  db.Store(open(cfg.Active()))
+  if Debug { log_server_info() }
+}
 
-  if cfg.Socket != "" {
-    target = fmt.Sprintf("unix(%s)", cfg.Socket)
-  } else {
-    target = fmt.Sprintf("tcp(%s:%d)", cfg.Host, cfg.Port)
+// Builds a new connection pool with given configuration and swaps it with the 
+// current one, so changed hosts, credentials or pool sizes take effect without 
+// restarting the process. Queries already running on the old pool are 
+// finished before it is closed.
+//
+// It panics and keeps the current pool when the new one can't connect.
+func Reload(cfg *Config) {
+  old := db.Swap(open(cfg.Active()))
+  reset_schema_cache()
+  if Debug { log_server_info() }
+  if old != nil {
+    if err := old.Close(); err != nil { Logger.Println(err) }
   }
+}
 
-  args := []interface{}{cfg.Username, cfg.Password, target, cfg.DBName }
-  connect_string := fmt.Sprintf("%s:%s@%s/%s?charset=utf8", args...)
-  var err error
-  db, err = sql.Open("mysql", connect_string)
-  if err != nil { panic(err) }
+// Sets an existing connection pool as the default one, e.g. the pool of a 
+// test driver like sqlmock, and returns the previous pool without closing it.
+func InitDB(pool *sql.DB) *sql.DB {
+  reset_schema_cache()
+  return db.Swap(pool)
+}
 
-  err = db.Ping()
-  if err != nil { panic(err) }
+// Closes the connection pool, and the pools of named connections opened by 
+// `Connect(...)`. Queries already running are finished before it returns, new 
+// queries fail with "sql: database is closed".
+func Close() error {
+  err := close_connections()
+  pool := db.Load()
+  if pool == nil { return err }
+  if close_err := pool.Close(); close_err != nil { err = close_err }
+  return err
+}
+
+// Closes the connection pool like `Close()`, but stops waiting for running 
+// queries when `ctx` is done and returns its error. The pool is still closed 
+// once those queries are finished.
+//
+// Example:
+//   ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Second)
+//   defer cancel()
+//   if err := mysql.Shutdown(ctx); err != nil { log.Println(err) }
+func Shutdown(ctx context.Context) error {
+  done := make(chan error, 1)
+  go func() { done <- Close() }()
+
+  select {
+  case err := <-done:
+    return err
+  case <-ctx.Done():
+    return ctx.Err()
+  }
+}
+
+func open(cfg *Config) *sql.DB {
+  pool := sql.OpenDB(sampling_connector{with_session(new_connector(cfg), cfg)})
+
+  pool.SetMaxOpenConns(cfg.MaxOpenConns)
+  if cfg.MaxIdleConns != 0 { pool.SetMaxIdleConns(cfg.MaxIdleConns) }
+  pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+  pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+  if err := pool.Ping(); err != nil {
+    pool.Close()
+    panic(err)
+  }
+  return pool
 }
 
 // Retrieve data from specified `table` with the given `where` condition and 
 // options.
 //
 // Parameters:
-//   - `table`: name of the table to perform the SELECT query on
-//   - `where`: conditions to be used in the WHERE clause of the query
+//   - `table`: name of the table to perform the SELECT query on, optionally 
+//              followed by an alias, e.g. "users u"
+//   - `where`: conditions to be used in the WHERE clause of the query. A key 
+//              may end with an operator, e.g. `{"age >=": 18}`. Supported 
+//              operators: =, !=, <>, <, <=, >, >=, <=>, IN, NOT IN, LIKE, 
+//              NOT LIKE, BETWEEN, NOT BETWEEN. A nil value with != becomes 
+//              `IS NOT NULL`, a slice becomes IN, an empty slice matches no 
+//              row, see `EmptyInError`. BETWEEN takes a slice of 2 values or 
+//              a `Range`
 //   - `options`: Optional map specify additional options
 // Options:
 //   - `column`: string, specify single column to return
-//   - `columns`: string array for multiple columns to return
+//   - `columns`: string array for multiple columns to return, or an 
+//                `[]interface{}` mixing names and expressions like 
+//                `mysql.Raw("COUNT(*) AS cnt")` which are not escaped
+//   - `distinct`: bool, return only distinct rows
 //   - `order`: string, order of the results
 //   - `offset`: int, this option will be discarded without limit
 //   - `limit`: int, maximum number of results
+//   - `join`: `Join` or `[]Join`, tables to join
+//   - `group`: string or string array, columns of the GROUP BY clause
+//   - `having`: conditions of the HAVING clause, either a map in the same 
+//               format as `where` or an expression like 
+//               `mysql.Raw("COUNT(*) > ?", 5)`
+//   - `from`: `mysql.Subquery(...)`, select from the subquery instead, 
+//             `table` is its alias
+//   - `with`: `mysql.CTE` or `[]mysql.CTE`, common table expressions of a 
+//             WITH clause, MySQL 8
+//   - `windows`: map[string]string, named window definitions of the WINDOW 
+//                clause used by `Over(...)` columns, MySQL 8
+//   - `typed`: bool, return values as Go types instead of strings, 
+//              `TypedValues` by default
+//   - `null_as_nil`: bool, return NULL values as `nil` instead of "", 
+//                    `NullAsNil` by default
+//   - `decode_json`: bool, decode values of JSON columns, `DecodeJSON` by 
+//                    default
+//   - `json`: string array, other columns to decode as JSON
+//   - `with_deleted`: bool, include soft-deleted rows, see `Model`
+//   - `unbounded`: bool, don't limit the query to `MaxSelectLimit` rows
+//   - `big_result`: bool, add the SQL_BIG_RESULT hint, the default on 
+//                   analytic connections
+//   - `lock`: string, lock the selected rows until the end of the 
+//             transaction, "update" for FOR UPDATE or "share" for 
+//             LOCK IN SHARE MODE. "update nowait" and 
+//             "update skip locked" are also supported
+//   - `tags`: map[string]string, tags of the query comment added to 
+//             `QueryTags`, also an option of `Update(...)` and `Delete(...)`
+//   - `use_index`, `force_index`, `ignore_index`: string or string array, 
+//     index hints of the table, also options of `Update(...)` and 
+//     `Delete(...)` without order and limit
+//   - `hints`: string or string array, optimizer hints like 
+//              "MAX_EXECUTION_TIME(1000)" or "NO_INDEX_MERGE(users)", also an 
+//              option of `Update(...)` and `Delete(...)`
+//   - `tenant`: string, tenant the tables are routed to instead of the one of 
+//               the context, see `WithTenant(...)`, also an option of 
+//               `Update(...)` and `Delete(...)`
 //
 // Returns:
 //   - []map[string]interface{}: rows data returned by the query
@@ -114,66 +334,110 @@ func Init(cfg *Config) {
 //   where   := _json{"user_id": user_id}
 //   options := _json{"order": "created_at DESC", "limit": 30}
 //   rows    := mysql.Select("producst", where, optioins)
-func Select(
+//
+//   // SELECT `u`.*, `p`.`name` FROM `users` AS `u` 
+//   //   INNER JOIN `profiles` AS `p` ON `p`.`user_id` = `u`.`id` 
+//   //   WHERE `u`.`active` = ?
+//   rows = mysql.Select("users u", _json{"u.active": 1}, _json{
+//     "columns": []string{"u.*", "p.name"},
+//     "join": mysql.Join{
+//       Table: "profiles p",
+//       On:    _json{"p.user_id": mysql.Ident("u.id")},
+//     },
+//   })
+func (d *DB) Select(
   table string,
   where map[string]interface{},
   args ...map[string]interface{},
 ) []map[string]interface{} {
   var options map[string]interface{}
   if len(args) > 0 { options = args[0] }
+  if _, ok := options["limit"].(int); d.analytic && !ok {
+    panic(fmt.Errorf(
+      "mysql: select of %q on an analytic connection needs a limit, " +
+      "use SelectEach or SelectInBatches to stream results", table,
+    ))
+  }
+  options = d.select_options(options)
+  options, limited := strict_options(options)
 
   cols := prepare_columns(options)
-  w := prepare_where(where)
-
-  order  := order_query(options)
-  limit  := limit_query(options, true)
-  format := "SELECT %s FROM %s%s%s%s;"
-  query := fmt.Sprintf(format, cols, EscapeId(table), w.query, order, limit)
-  rows  := ExecQuery(query, w.values...)
+  query, values := build_select(cols, table, where, options, true)
+  if limited && SelectLimitedHandler != nil { SelectLimitedHandler(table, query) }
+  rows := d.ExecQuery(query+";", values...)
   defer rows.Close()
 
-  columns, err := rows.Columns()
-  if err != nil { panic(err) }
-
-  values := make([]sql.RawBytes, len(columns))
-  // Make a slice of pointers to the values
-  valuePtrs := make([]interface{}, len(columns))
-  for i := range values {
-    valuePtrs[i] = &values[i]
-  }
-
-  var results []map[string]interface{}
-  for rows.Next() {
-    if err := rows.Scan(valuePtrs...); err != nil {
-      panic(err)
-    }
-    // Create a map to hold the column names and values
-    result := map[string]interface{}{}
-    for i, col := range columns {
-      result[col] = string(values[i])
-    }
-    results = append(results, result)
-  }
-
+  results := scan_maps(rows, options)
+  for _, row := range results { model_row(table, row) }
   return results
 }
 
+// Same api with `Select(...)` method except rows are passed to `fn` one at a 
+// time while they are read, instead of being buffered into a slice. Use it for 
+// large result sets. Iteration stops at the first error returned by `fn`, 
+// which is returned.
+//
+// Example:
+//   err := mysql.SelectEach("events", where, nil, func(row map[string]interface{}) error {
+//     return encoder.Encode(row)
+//   })
+func (d *DB) SelectEach(
+  table string,
+  where, options map[string]interface{},
+  fn func(row map[string]interface{}) error,
+) error {
+  options = d.select_options(options)
+  cols := prepare_columns(options)
+  query, values := build_select(cols, table, where, options, true)
+  rows := d.ExecQuery(query+";", values...)
+  defer rows.Close()
+  return each_map(rows, options, func(row map[string]interface{}) error {
+    model_row(table, row)
+    return fn(row)
+  })
+}
+
 // Same api with `Select(...)` method except it will override `options["limit"]` 
 // to set 1 and returns a single row if found.
-func First(
+//
+// Example:
+//   err := mysql.Transaction(ctx, func(ctx context.Context) error {
+//     db      := mysql.WithContext(ctx)
+//     account := db.First("accounts", where, map[string]interface{}{"lock": "update"})
+//     ...
+//   })
+func (d *DB) First(
   table string,
   where map[string]interface{},
   options ...map[string]interface{},
 ) map[string]interface{} {
   set_limit_option(&options)
-  results := Select(table, where, options...)
+  results := d.Select(table, where, options...)
   if len(results) == 1 {
     return results[0]
   }
   return nil
 }
 
-// Inserts data into a table.
+// Same api with `First(...)` method except it returns errors instead of 
+// panicking, and `ErrNoRows` when no row matches.
+//
+// Example:
+//   user, err := mysql.Find("users", _json{"id": id})
+//   if mysql.IsNotFound(err) { return nil, ErrUnknownUser }
+func (d *DB) Find(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (row map[string]interface{}, err error) {
+  defer RecoverError(&err)
+  row = d.First(table, where, options...)
+  if row == nil { return nil, ErrNoRows }
+  return row, nil
+}
+
+// Inserts data into a table. Defaults registered by `RegisterDefaults(...)` 
+// are applied to absent columns.
 //
 // Parameters:
 //   - `table`: The name of the table to insert into
@@ -183,23 +447,21 @@ func First(
 // Returns:
 //   - sql.Result: Result of the insert statement execution
 // TODO: update this method to support multiple rows
-func Insert(table string, data map[string]interface{}) sql.Result {
-  var values       []any
-  var columns      []string
-  var placeholders []string
-
-  for k, v := range data {
-    values       = append(values, v)
-    columns      = append(columns, EscapeId(k))
-    placeholders = append(placeholders, "?")
-  }
+func (d *DB) Insert(table string, data map[string]interface{}) sql.Result {
+  return d.insert_into("INSERT INTO", table, model_data(table, data, true))
+}
 
-  cols  := strings.Join(columns, ", ")
-  vals  := strings.Join(placeholders, ", ")
-  args  := []interface{}{ EscapeId(table), cols, vals }
-  query := fmt.Sprintf("INSERT INTO %s(%s) VALUES(%s)", args...)
+// Same api with `Insert(...)` method except it uses `INSERT IGNORE`, so rows 
+// which would cause duplicate key errors are silently skipped.
+func (d *DB) InsertIgnore(table string, data map[string]interface{}) sql.Result {
+  return d.insert_into("INSERT IGNORE INTO", table, model_data(table, data, true))
+}
 
-  return Exec(query, values...)
+// Same api with `Insert(...)` method except it uses `REPLACE INTO`, so an old 
+// row which has the same value for a PRIMARY KEY or a UNIQUE index is deleted 
+// before the new row is inserted.
+func (d *DB) Replace(table string, data map[string]interface{}) sql.Result {
+  return d.insert_into("REPLACE INTO", table, model_data(table, data, true))
 }
 
 // Insert a single row data into a table.
@@ -211,13 +473,37 @@ func Insert(table string, data map[string]interface{}) sql.Result {
 //
 // Returns:
 //   - sql.Result: Result of the insert statement execution
-func InsertRow(table string, data map[string]interface{}) sql.Result {
+func (d *DB) InsertRow(table string, data map[string]interface{}) sql.Result {
+  data = model_data(table, data, true)
+  before_insert(table, data)
   set, values := prepare_set(data)
-  query := fmt.Sprintf("INSERT INTO %s SET %s;", table, set)
-  return Exec(query, values...)
+  query  := fmt.Sprintf("INSERT INTO %s SET %s;", d.tenant_table(table), set)
+  result := d.Exec(query, values...)
+  after_insert(table, data, result)
+  return result
 }
 
-// Updates the data in a table with specified conditions.
+// Inserts a single row like `Insert(...)` and returns its AUTO_INCREMENT id.
+//
+// Parameters:
+//   - `table`: The name of the table to insert into
+//   - `data`: A map of the column names and values to be inserted into the 
+//               table
+//
+// Returns:
+//   - int64: id of the inserted row
+//   - error: error of the insert, e.g. a duplicate key
+//
+// Example:
+//   id, err := mysql.InsertGetID("users", _json{"email": email})
+//   if err != nil { return err }
+func (d *DB) InsertGetID(table string, data map[string]interface{}) (id int64, err error) {
+  defer RecoverError(&err)
+  return d.Insert(table, data).LastInsertId()
+}
+
+// Updates the data in a table with specified conditions. Tables with a 
+// `Model.Version` column are updated with optimistic locking.
 //
 // Parameters:
 //   - `table`: The name of the table to update
@@ -229,7 +515,7 @@ func InsertRow(table string, data map[string]interface{}) sql.Result {
 //
 // Returns:
 //   - sql.Result: Result of the update query
-func Update(
+func (d *DB) Update(
   table string,
   data, where map[string]interface{},
   args ...map[string]interface{},
@@ -237,30 +523,61 @@ func Update(
   var options map[string]interface{}
   if len(args) > 0 { options = args[0] }
 
-  set, values := prepare_set(data)
-  w := prepare_where(where)
+  options = d.tenant_options(options)
+  before_update(table, data, where)
+  result := d.update(table, data, where, options)
+  after_update(table, data, where, result)
+  return result
+}
+
+func (d *DB) update(
+  table string,
+  data, where, options map[string]interface{},
+) sql.Result {
+  query, values := build_update(table, data, where, options)
+  result := d.Exec(query, values...)
+  if _, _, checked := model_version(table, data, where); checked {
+    if count, err := result.RowsAffected(); err == nil && count == 0 {
+      panic(ErrStaleRow)
+    }
+  }
+  return result
+}
+
+func build_update(
+  table string,
+  data, where, options map[string]interface{},
+) (string, []interface{}) {
+  data, where, _ = model_version(table, data, where)
+  set, values := prepare_set(model_data(table, data, false))
+  w := prepare_where(model_where(table, where, options))
   values = append(values, w.values...)
-  
+
   order  := order_query(options)
   limit  := limit_query(options, false)
 
-  params := []interface{}{ EscapeId(table), set, w.query, order, limit }
-  query  := fmt.Sprintf("UPDATE %s SET %s%s%s%s;", params...)
-  return Exec(query, values...)
+  params := []interface{}{
+    option_tags(options), optimizer_hints(options),
+    EscapeId(route_table(table, option_tenant(options))), index_hints(options), set, w.query, order, limit,
+  }
+  query := fmt.Sprintf("%sUPDATE %s%s%s SET %s%s%s%s;", params...)
+  return query, values
 }
 
 // Same api with `Update(...)` method except it will override `options["limit"]` 
 // to set 1.
-func UpdateFirst(
+func (d *DB) UpdateFirst(
   table string,
   data, where map[string]interface{},
   options ...map[string]interface{},
 ) sql.Result {
   set_limit_option(&options)
-  return Update(table, data, where, options...)
+  return d.Update(table, data, where, options...)
 }
 
-// Deletes data from a specified table.
+// Deletes data from a specified table. When the table's `Model` has a 
+// `SoftDelete` column, rows are marked as deleted instead, unless the option 
+// `"force": true` is given.
 //
 // Parameters:
 //   - `table`: The name of the table
@@ -268,7 +585,7 @@ func UpdateFirst(
 //   - `options`: Additional options, such as "order" or "limit"
 // Returns:
 //   - sql.Result: Result of the delete operation
-func Delete(
+func (d *DB) Delete(
   table string,
   where map[string]interface{},
   args ...map[string]interface{},
@@ -276,6 +593,26 @@ func Delete(
   var options map[string]interface{}
   if len(args) > 0 { options = args[0] }
 
+  options = d.tenant_options(options)
+  before_delete(table, where)
+  query, values := build_delete(table, where, options)
+  result := d.Exec(query, values...)
+  after_delete(table, where, result)
+  return result
+}
+
+// Builds the DELETE query, or the UPDATE query of a soft delete.
+func build_delete(table string, where, options map[string]interface{}) (string, []interface{}) {
+  model := ModelOf(table)
+  if model != nil && model.SoftDelete != "" {
+    if force, _ := options["force"].(bool); !force {
+      data := map[string]interface{}{model.SoftDelete: Raw("NOW()")}
+      return build_update(table, data, where, options)
+    }
+  }
+
+  // A forced delete removes soft deleted rows as well, UUIDs still apply
+  if model != nil { where = uuid_where(model, where) }
   w := prepare_where(where)
   order := ""
   if val, ok := options["order"].(string); ok {
@@ -287,19 +624,32 @@ func Delete(
 		limit = fmt.Sprintf(" LIMIT %d", val)
 	}
 
-  query := fmt.Sprintf("DELETE FROM %s%s%s%s;", table, w.query, order, limit)
-  return Exec(query, w.values...)
+  // Index hints need the multiple-table syntax, without ORDER BY and LIMIT
+  routed := route_table(table, option_tenant(options))
+  target, from := "", routed
+  if hints := index_hints(options); hints != "" {
+    if order != "" || limit != "" {
+      panic(fmt.Errorf("mysql: index hints of a delete can't be used with order or limit"))
+    }
+    target, from = EscapeId(routed) + " ", EscapeId(routed) + hints
+  }
+
+  query := fmt.Sprintf(
+    "%sDELETE %s%sFROM %s%s%s%s;",
+    option_tags(options), optimizer_hints(options), target, from, w.query, order, limit,
+  )
+  return query, w.values
 }
 
 // Same api with `Delete(...)` method except it will override `options["limit"]` 
 // to set 1.
-func DeleteFirst(
+func (d *DB) DeleteFirst(
   table string,
   where map[string]interface{},
   options ...map[string]interface{},
 ) sql.Result {
   set_limit_option(&options)
-  return Delete(table, where, options...)
+  return d.Delete(table, where, options...)
 }
 
 // Executes an user defined query with values. Which is useful when user wants 
@@ -310,13 +660,42 @@ func DeleteFirst(
 //   - `values`: parameters to be passed to the query
 // Returns:
 //   - *sql.Rows: SQL rows cursor
-func ExecQuery(query string, values ...interface{}) *sql.Rows {
-  if Debug { log.Println(query, values) }
-  rows, err := db.Query(query, values...)
+func (d *DB) ExecQuery(query string, values ...interface{}) *sql.Rows {
+  rows, err := d.query(query, values...)
   if err != nil { handle_error(err, query, values) }
   return rows
 }
 
+// Executes an user defined query returning at most one row. Errors are 
+// deferred until `sql.Row.Scan(...)` is called, which returns 
+// `sql.ErrNoRows` when there is no row.
+//
+// Example:
+//   var name string
+//   err := mysql.ExecQueryRow("SELECT name FROM users WHERE id = ?", id).Scan(&name)
+func (d *DB) ExecQueryRow(query string, values ...interface{}) *sql.Row {
+  return d.query_row(query, values...)
+}
+
+// Executes an user defined query and scans the columns of its first row into 
+// `dest`, for single value lookups. It returns `sql.ErrNoRows` when there is 
+// no row.
+//
+// Parameters:
+//   - `query`: the query to be executed
+//   - `values`: parameters to be passed to the query
+//   - `dest`: pointers receiving the columns of the row
+// Returns:
+//   - error: `sql.ErrNoRows`, a query error or a scan error
+//
+// Example:
+//   var count int64
+//   err := mysql.ScanOne("SELECT COUNT(*) FROM orders WHERE user_id = ?", 
+//     []interface{}{user_id}, &count)
+func (d *DB) ScanOne(query string, values []interface{}, dest ...interface{}) error {
+  return d.scan_row(dest, query, values...)
+}
+
 // Executes an user defined query.
 //
 // Parameters:
@@ -324,16 +703,156 @@ func ExecQuery(query string, values ...interface{}) *sql.Rows {
 //   - `values`: parameters to be passed to the query
 // Returns:
 //   - sql.Result: A Result summarizes an executed SQL query
-func Exec(query string, values ...interface{}) sql.Result {
-  if Debug { log.Println(query, values) }
-  result, err := db.Exec(query, values...)
+func (d *DB) Exec(query string, values ...interface{}) sql.Result {
+  result, err := d.exec(query, values...)
   if err != nil { handle_error(err, query, values) }
   return result
 }
 
-func handle_error(err error, query string, values ...interface{}) {
+func (d *DB) insert_into(
+  statement, table string,
+  data map[string]interface{},
+  suffix ...string,
+) sql.Result {
+  before_insert(table, data)
+  query, values := build_insert(statement, d.tenant_table(table), data, suffix...)
+  result := d.Exec(query, values...)
+  after_insert(table, data, result)
+  return result
+}
+
+func build_insert(
+  statement, table string,
+  data map[string]interface{},
+  suffix ...string,
+) (string, []interface{}) {
+  var values       []any
+  var columns      []string
+  var placeholders []string
+
+  for k, v := range data {
+    columns = append(columns, EscapeId(k))
+    if expr, ok := v.(*Expression); ok {
+      values       = append(values, expr.values...)
+      placeholders = append(placeholders, expr.query)
+    } else {
+      values       = append(values, v)
+      placeholders = append(placeholders, "?")
+    }
+  }
+
+  cols  := strings.Join(columns, ", ")
+  vals  := strings.Join(placeholders, ", ")
+  args  := []interface{}{ statement, EscapeId(table), cols, vals }
+  query := fmt.Sprintf("%s %s(%s) VALUES(%s)", args...)
+  if len(suffix) > 0 { query += suffix[0] }
+  return query, values
+}
+
+func detect_socket() string {
+  for _, path := range socket_paths {
+    info, err := os.Stat(path)
+    if err == nil && info.Mode()&os.ModeSocket != 0 {
+      return path
+    }
+  }
+  return ""
+}
+
+// Builds a SELECT query without the trailing semicolon. ORDER BY and LIMIT 
+// clauses are skipped when `bounded` is false.
+func build_select(
+  cols *Expression,
+  table string,
+  where, options map[string]interface{},
+  bounded bool,
+) (string, []interface{}) {
+  with, values := with_query(options)
+  values = append(values, cols.values...)
+  from := escape_tenant_table(table, option_tenant(options)) + index_hints(options)
+  if source, ok := options["from"].(*Expression); ok {
+    from   = source.query + " AS " + EscapeId(table)
+    values = append(values, source.values...)
+  }
+  join, join_values := join_query(options)
+  values = append(values, join_values...)
+  w := prepare_where(model_where(table, where, options))
+  values = append(values, w.values...)
+  group, having := group_query(options)
+  values = append(values, having...)
+
+  columns := cols.query
+  if big, _ := options["big_result"].(bool); big { columns = "SQL_BIG_RESULT " + columns }
+  if distinct, _ := options["distinct"].(bool); distinct { columns = "DISTINCT " + columns }
+  columns = optimizer_hints(options) + columns
+
+  query := fmt.Sprintf(
+    "%s%sSELECT %s FROM %s%s%s%s%s",
+    option_tags(options), with, columns, from, join, w.query, group,
+    window_query(options),
+  )
+  if bounded {
+    query += order_query(options) + limit_query(options, true) + lock_query(options)
+  }
+  return query, values
+}
+
+func scan_maps(rows *sql.Rows, options map[string]interface{}) []map[string]interface{} {
+  var results []map[string]interface{}
+  each_map(rows, options, func(result map[string]interface{}) error {
+    results = append(results, result)
+    return nil
+  })
+  return results
+}
+
+// Calls `fn` for every row until it returns an error, which is returned. 
+// Values are strings, or converted by the "typed", "null_as_nil" and 
+// "decode_json" options.
+func each_map(
+  rows *sql.Rows,
+  options map[string]interface{},
+  fn func(map[string]interface{}) error,
+) error {
+  columns, err := rows.Columns()
+  if err != nil { panic(err) }
+
+  converters := value_converters(rows, options)
+  var raw    []sql.RawBytes
+  var values []interface{}
+  // Make a slice of pointers to the values
+  valuePtrs := make([]interface{}, len(columns))
+  if converters != nil {
+    values = make([]interface{}, len(columns))
+    for i := range values { valuePtrs[i] = &values[i] }
+  } else {
+    raw = make([]sql.RawBytes, len(columns))
+    for i := range raw { valuePtrs[i] = &raw[i] }
+  }
+
+  for rows.Next() {
+    if err := rows.Scan(valuePtrs...); err != nil {
+      panic(err)
+    }
+    // Create a map to hold the column names and values
+    result := map[string]interface{}{}
+    for i, col := range columns {
+      if converters == nil {
+        result[col] = string(raw[i])
+      } else {
+        result[col] = converters[i](values[i])
+      }
+    }
+    if err := fn(result); err != nil { return err }
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+
+  return nil
+}
+
+func handle_error(err error, query string, values []interface{}) {
   if mysql_err, ok := err.(*m.MySQLError); ok {
-    panic(&Error{query, values, mysql_err})
+    panic(&Error{query, redact(query, values), mysql_err})
   }
   panic(err)
 }
@@ -346,6 +865,44 @@ func order_query(options map[string]interface{}) string {
   return order
 }
 
+func group_query(options map[string]interface{}) (string, []interface{}) {
+  var columns []string
+  switch value := options["group"].(type) {
+  case string:   columns = []string{value}
+  case []string: columns = value
+  case nil:
+  default:
+    panic(fmt.Errorf("mysql: invalid group option type %T", value))
+  }
+
+  query := ""
+  if len(columns) > 0 {
+    escaped := make([]string, len(columns))
+    for i, column := range columns {
+      escaped[i] = EscapeId(column)
+    }
+    query = " GROUP BY " + strings.Join(escaped, ", ")
+  }
+
+  // HAVING without GROUP BY filters the single group of aggregates
+
+  having := options["having"]
+  if expr, ok := having.(*Expression); ok {
+    return query + " HAVING " + expr.query, expr.values
+  }
+  if having != nil {
+    conditions, ok := as_map(having)
+    if !ok {
+      panic(fmt.Errorf("mysql: invalid having option type %T", having))
+    }
+    if len(conditions) > 0 {
+      having, values := prepare_conditions(conditions)
+      return query + " HAVING " + having, values
+    }
+  }
+  return query, nil
+}
+
 func limit_query(
   options map[string]interface{},
   has_offset bool,
@@ -363,52 +920,160 @@ func limit_query(
 	return ""
 }
 
-func prepare_columns(options map[string]interface{}) string {
-  field, ok := options["column"].(string)
-  if ok { return EscapeId(field) }
+// Locking clauses of the "lock" option.
+var lock_clauses = map[string]string{
+  "update":             " FOR UPDATE",
+  "update nowait":      " FOR UPDATE NOWAIT",
+  "update skip locked": " FOR UPDATE SKIP LOCKED",
+  "share":              " LOCK IN SHARE MODE",
+}
+
+func lock_query(options map[string]interface{}) string {
+  lock, ok := options["lock"].(string)
+  if !ok || lock == "" { return "" }
+  clause, ok := lock_clauses[strings.ToLower(lock)]
+  if !ok { panic(fmt.Errorf("mysql: invalid lock option %q", lock)) }
+  return clause
+}
 
-  fields, ok := options["columns"].([]string)
-  if !ok { return "*" }
+// Builds the column list of a SELECT query from the options "column" and 
+// "columns". Columns are escaped, except `*Expression` columns like 
+// `mysql.Raw("COUNT(*) AS cnt")`.
+func prepare_columns(options map[string]interface{}) *Expression {
+  var fields []interface{}
+  switch value := options["column"].(type) {
+  case string, *Expression:
+    fields = []interface{}{value}
+  default:
+    switch value := options["columns"].(type) {
+    case []string:
+      for _, field := range value { fields = append(fields, field) }
+    case []interface{}:
+      fields = value
+    }
+  }
+  if len(fields) == 0 { return Raw("*") }
 
-  for i, f := range fields {
-    fields[i] = EscapeId(f)
+  columns := make([]string, len(fields))
+  var values []interface{}
+  for i, field := range fields {
+    switch field := field.(type) {
+    case string:
+      columns[i] = EscapeId(field)
+    case *Expression:
+      columns[i] = field.query
+      values = append(values, field.values...)
+    default:
+      panic(fmt.Errorf("mysql: invalid column %v of type %T", field, field))
+    }
   }
-  return strings.Join(fields, ", ")
+  return Raw(strings.Join(columns, ", "), values...)
+}
+
+// Operators allowed after the column name in a where map key. For example 
+// `{"age >": 18}` or `{"name LIKE": "%foo%"}`.
+var operators = map[string]bool{
+  "=": true, "!=": true, "<>": true,
+  "<": true, "<=": true, ">": true, ">=": true,
+  "IN": true, "NOT IN": true, "LIKE": true, "NOT LIKE": true, "<=>": true,
+  "BETWEEN": true, "NOT BETWEEN": true,
+}
+
+func parse_where_key(key string) (string, string) {
+  key = strings.TrimSpace(key)
+  column, operator, found := strings.Cut(key, " ")
+  if !found { return column, "" }
+
+  operator = strings.ToUpper(strings.Join(strings.Fields(operator), " "))
+  if !operators[operator] {
+    panic(fmt.Errorf("mysql: unsupported operator %q in where key %q", operator, key))
+  }
+  return column, operator
 }
 
 func prepare_where(where map[string]interface{}) _where {
+  query, values := prepare_conditions(where)
+  if query != "" { query = " WHERE " + query }
+  return _where{query: query, values: values}
+}
+
+func prepare_conditions(where map[string]interface{}) (string, []interface{}) {
   var values []interface{}
-	var query string
-	if where != nil {
-    conditions := []string{}
-
-    for key, value := range where {
-      key = EscapeId(key)
-      if value == nil {
-        conditions = append(conditions, key+" IS NULL")
-      } else if reflect.TypeOf(value).Kind() == reflect.Slice {
-        v := reflect.ValueOf(value)
-        placeholders := []string{}
-        for i := 0; i < v.Len(); i++ {
-          values = append(values, v.Index(i).Interface())
-          placeholders = append(placeholders, "?")
-        }
-        query := fmt.Sprintf("%sIN(%s)", key, strings.Join(placeholders, ", "))
-        conditions = append(conditions, query)
-      } else {
-        if reflect.TypeOf(value).Kind() == reflect.Map {
-          bytes, _ := json.Marshal(value)
-          value = string(bytes)
-        }
-        values = append(values, value)
-        conditions = append(conditions, key+" = ?")
+  conditions := []string{}
+
+  for key, value := range where {
+    condition, args := prepare_condition(key, value)
+    conditions = append(conditions, condition)
+    values     = append(values, args...)
+  }
+
+  return strings.Join(conditions, " AND "), values
+}
+
+func prepare_condition(key string, value interface{}) (string, []interface{}) {
+  if key == builder_where {
+    expr := value.(*Expression)
+    return "(" + expr.query + ")", expr.values
+  }
+  column, operator := parse_where_key(key)
+  column = EscapeId(column)
+
+  if c, ok := value.(condition); ok {
+    return c.condition(column, operator)
+  }
+
+  // Valuers are single values even when they're slices or maps
+  value, is_valuer := resolve_valuer(value)
+
+  if value == nil && (operator == "" || operator == "=") {
+    return column + " IS NULL", nil
+  }
+  if value == nil && (operator == "!=" || operator == "<>") {
+    return column + " IS NOT NULL", nil
+  }
+
+  // Binary values like UUIDs are compared as a whole
+  _, binary := value.([]byte)
+  if value != nil && !binary && !is_valuer && reflect.TypeOf(value).Kind() == reflect.Slice {
+    v := reflect.ValueOf(value)
+    if operator == "BETWEEN" || operator == "NOT BETWEEN" {
+      if v.Len() != 2 {
+        panic(fmt.Errorf("mysql: %s of %s needs 2 values, got %d", operator, column, v.Len()))
       }
+      return Range{v.Index(0).Interface(), v.Index(1).Interface()}.condition(column, operator)
     }
-
-    query = " WHERE "+strings.Join(conditions, " AND ")
+    switch operator {
+    case "", "IN": operator = "IN"
+    case "NOT IN":
+    default:
+      panic(fmt.Errorf("mysql: operator %q does not accept a slice", operator))
+    }
+    var values []interface{}
+    if v.Len() == 0 {
+      if EmptyInError { panic(fmt.Errorf("%w: %s", ErrEmptyIn, column)) }
+      if operator == "IN" { return "1 = 0", nil }
+      return "1 = 1", nil
+    }
+    placeholders := []string{}
+    for i := 0; i < v.Len(); i++ {
+      values = append(values, v.Index(i).Interface())
+      placeholders = append(placeholders, "?")
+    }
+    list := strings.Join(placeholders, ", ")
+    return fmt.Sprintf("%s %s(%s)", column, operator, list), values
   }
 
-  return _where{query: query, values: values}
+  if value != nil && !is_valuer && reflect.TypeOf(value).Kind() == reflect.Map {
+    bytes, _ := json.Marshal(value)
+    value = string(bytes)
+  }
+  switch operator {
+  case "", "IN": operator = "="
+  case "NOT IN": operator = "!="
+  case "BETWEEN", "NOT BETWEEN":
+    panic(fmt.Errorf("mysql: %s of %s needs a slice of 2 values or a Range", operator, column))
+  }
+  return column + " " + operator + " ?", []interface{}{value}
 }
 
 func prepare_set(data map[string]interface{}) (string, []interface{}) {
@@ -418,6 +1083,9 @@ func prepare_set(data map[string]interface{}) (string, []interface{}) {
 	for key, value := range data {
 		if value == nil {
 			columns[i] = fmt.Sprintf("%s = NULL", EscapeId(key))
+		} else if expr, ok := value.(*Expression); ok {
+			values     = append(values, expr.values...)
+			columns[i] = fmt.Sprintf("%s = %s", EscapeId(key), expr.query)
 		} else {
 			values     = append(values, value)
 			columns[i] = fmt.Sprintf("%s = ?", EscapeId(key))
@@ -427,9 +1095,48 @@ func prepare_set(data map[string]interface{}) (string, []interface{}) {
 	return strings.Join(columns, ", "), values
 }
 
+// Returns the driver value of a valuer of a where map, nil for an invalid 
+// `sql.NullString` or a nil pointer. The resolved value is bound, so 
+// `Value()` is called once per query.
+func resolve_valuer(value interface{}) (interface{}, bool) {
+  valuer, ok := value.(driver.Valuer)
+  if !ok { return value, false }
+  if v := reflect.ValueOf(valuer); v.Kind() == reflect.Pointer && v.IsNil() { return nil, true }
+  resolved, err := valuer.Value()
+  if err != nil { panic(err) }
+  return resolved, true
+}
+
+// Converts named map types like `type _json map[string]interface{}`.
+func as_map(value interface{}) (map[string]interface{}, bool) {
+  if m, ok := value.(map[string]interface{}); ok { return m, true }
+  target := reflect.TypeOf(map[string]interface{}{})
+  v := reflect.ValueOf(value)
+  if v.IsValid() && v.Type().ConvertibleTo(target) {
+    return v.Convert(target).Interface().(map[string]interface{}), true
+  }
+  return nil, false
+}
+
+// Sets the limit to 1 on a copy of the options, so the caller's map, e.g. 
+// of a `Query` builder, keeps its limit.
 func set_limit_option(options *[]map[string]interface{}) {
-  switch len(*options) {
-  case 0: *options = []map[string]interface{}{ {"limit": 1} }
-  case 1: (*options)[0]["limit"] = 1
+  copied := map[string]interface{}{}
+  if len(*options) > 0 {
+    for key, value := range (*options)[0] { copied[key] = value }
   }
-}
\ No newline at end of file
+  copied["limit"] = 1
+  *options = []map[string]interface{}{copied}
+}
+
+// Returns the options of a SELECT query of this handle, with the 
+// SQL_BIG_RESULT hint on analytic connections.
+func (d *DB) select_options(options map[string]interface{}) map[string]interface{} {
+  options = d.tenant_options(options)
+  if !d.analytic { return options }
+  if _, ok := options["big_result"]; ok { return options }
+
+  copied := map[string]interface{}{"big_result": true}
+  for key, value := range options { copied[key] = value }
+  return copied
+}
@@ -0,0 +1,244 @@
+// Test backend of package `github.com/je3f0o/go-jeefo-mysql`, so unit tests of 
+// services using it don't need a MySQL server. It records the generated 
+// queries with their values, and returns canned results for queries matching 
+// a stub.
+//
+// Example:
+//   func TestCreateUser(t *testing.T) {
+//     mock := mysqltest.New(t)
+//     mock.On("SELECT * FROM `users`").Rows([]string{"id", "name"}, []interface{}{1, "jeefo"})
+//     mock.On("INSERT INTO `users`").Result(42, 1)
+//
+//     id := CreateUser("jeefo")
+//
+//     if id != 42 { t.Fatal(id) }
+//     queries := mock.Queries()
+//     // code...
+//   }
+package mysqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+// Query run on the test backend. Transactions are recorded as the queries 
+// "BEGIN", "COMMIT" and "ROLLBACK".
+type Query struct {
+  SQL  string
+  Args []interface{}
+}
+
+// Test backend recording queries and returning the results of stubs.
+type Mock struct {
+  mu      sync.Mutex
+  queries []Query
+  stubs   []*Stub
+}
+
+// Result of queries containing a SQL fragment, registered by `Mock.On(...)`.
+type Stub struct {
+  fragment      string
+  columns       []string
+  rows          [][]interface{}
+  last_insert   int64
+  rows_affected int64
+  err           error
+}
+
+// Creates a test backend and sets it as the default connection until the end 
+// of the test, see `mysql.InitDB(...)`.
+func New(t testing.TB) *Mock {
+  mock := &Mock{}
+  pool := sql.OpenDB(connector{mock})
+  previous := mysql.InitDB(pool)
+  t.Cleanup(func() {
+    mysql.InitDB(previous)
+    pool.Close()
+  })
+  return mock
+}
+
+// Creates a handle of a test backend without changing the default connection, 
+// for code using `*mysql.DB` handles.
+func NewDB() (*mysql.DB, *Mock) {
+  mock := &Mock{}
+  return mysql.NewDB(sql.OpenDB(connector{mock})), mock
+}
+
+// Registers a stub for queries containing `fragment`. Stubs are matched in the 
+// order they were registered and can match any number of queries. Queries 
+// matching no stub return no rows, or no affected rows.
+func (m *Mock) On(fragment string) *Stub {
+  stub := &Stub{fragment: fragment}
+  m.mu.Lock()
+  m.stubs = append(m.stubs, stub)
+  m.mu.Unlock()
+  return stub
+}
+
+// Returns the recorded queries in order.
+func (m *Mock) Queries() []Query {
+  m.mu.Lock()
+  defer m.mu.Unlock()
+  return append([]Query{}, m.queries...)
+}
+
+// Returns the SQL of the recorded queries in order.
+func (m *Mock) SQL() []string {
+  m.mu.Lock()
+  defer m.mu.Unlock()
+  queries := make([]string, len(m.queries))
+  for i, query := range m.queries { queries[i] = query.SQL }
+  return queries
+}
+
+// Forgets recorded queries and stubs.
+func (m *Mock) Reset() {
+  m.mu.Lock()
+  m.queries = nil
+  m.stubs   = nil
+  m.mu.Unlock()
+}
+
+// Returns rows with `columns` for matching queries. Values are returned like 
+// MySQL returns them as text, e.g. 1 is "1" in result maps.
+func (s *Stub) Rows(columns []string, rows ...[]interface{}) *Stub {
+  s.columns = columns
+  s.rows    = rows
+  return s
+}
+
+// Returns the last insert id and the number of affected rows for matching 
+// statements.
+func (s *Stub) Result(last_insert_id, rows_affected int64) *Stub {
+  s.last_insert   = last_insert_id
+  s.rows_affected = rows_affected
+  return s
+}
+
+// Fails matching queries with `err`, e.g. a `*mysql.MySQLError` of the driver 
+// to test duplicate keys.
+func (s *Stub) Error(err error) *Stub {
+  s.err = err
+  return s
+}
+
+func (m *Mock) record(query string, args []driver.NamedValue) *Stub {
+  values := make([]interface{}, len(args))
+  for i, arg := range args { values[i] = arg.Value }
+
+  m.mu.Lock()
+  defer m.mu.Unlock()
+  m.queries = append(m.queries, Query{SQL: query, Args: values})
+  for _, stub := range m.stubs {
+    if strings.Contains(query, stub.fragment) { return stub }
+  }
+  return &Stub{}
+}
+
+type connector struct{ mock *Mock }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+  return &conn{c.mock}, nil
+}
+
+func (c connector) Driver() driver.Driver { return test_driver{} }
+
+type test_driver struct{}
+
+func (test_driver) Open(string) (driver.Conn, error) {
+  return nil, errors.New("mysqltest: the driver only connects through mysqltest.New")
+}
+
+type conn struct{ mock *Mock }
+
+func (c *conn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *conn) Close() error { return nil }
+func (c *conn) Begin() (driver.Tx, error) {
+  return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+  c.mock.record("BEGIN", nil)
+  return tx{c.mock}, nil
+}
+
+// Keeps the values as passed, so they are recorded unchanged.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *conn) QueryContext(
+  ctx context.Context,
+  query string,
+  args []driver.NamedValue,
+) (driver.Rows, error) {
+  // Like the driver, queries of a cancelled context aren't sent
+  if err := ctx.Err(); err != nil { return nil, err }
+  stub := c.mock.record(query, args)
+  if stub.err != nil { return nil, stub.err }
+  return &rows{columns: stub.columns, data: stub.rows}, nil
+}
+
+func (c *conn) ExecContext(
+  ctx context.Context,
+  query string,
+  args []driver.NamedValue,
+) (driver.Result, error) {
+  // Like the driver, queries of a cancelled context aren't sent
+  if err := ctx.Err(); err != nil { return nil, err }
+  stub := c.mock.record(query, args)
+  if stub.err != nil { return nil, stub.err }
+  return result{stub.last_insert, stub.rows_affected}, nil
+}
+
+type tx struct{ mock *Mock }
+
+func (t tx) Commit() error {
+  t.mock.record("COMMIT", nil)
+  return nil
+}
+
+func (t tx) Rollback() error {
+  t.mock.record("ROLLBACK", nil)
+  return nil
+}
+
+type result struct{ last_insert, rows_affected int64 }
+
+func (r result) LastInsertId() (int64, error) { return r.last_insert, nil }
+func (r result) RowsAffected() (int64, error) { return r.rows_affected, nil }
+
+type rows struct {
+  columns []string
+  data    [][]interface{}
+  i       int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+  if r.i >= len(r.data) { return io.EOF }
+  for i, value := range r.data[r.i] {
+    switch value := value.(type) {
+    case nil:
+      dest[i] = nil
+    case string:
+      dest[i] = []byte(value)
+    default:
+      converted, err := driver.DefaultParameterConverter.ConvertValue(value)
+      if err != nil { return err }
+      dest[i] = converted
+    }
+  }
+  r.i++
+  return nil
+}
@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Same api with `Exec(...)` method except the query uses named placeholders 
+// like `:name`, replaced by positional placeholders in the order they appear. 
+// A slice parameter is expanded into one placeholder per element, for IN 
+// clauses. Placeholders in quotes and comments, and the assignment operator 
+// `:=` are left as they are.
+//
+// Parameters:
+//   - `query`: the query to be executed
+//   - `params`: values of the named placeholders, without the colon
+// Returns:
+//   - sql.Result: Result of the query execution
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // UPDATE users SET status = ? WHERE id IN (?, ?, ?) AND status != ?
+//   mysql.ExecNamed(
+//     "UPDATE users SET status = :status WHERE id IN (:ids) AND status != :status",
+//     _json{"status": "banned", "ids": []int{1, 2, 3}},
+//   )
+func (d *DB) ExecNamed(query string, params map[string]interface{}) sql.Result {
+  query, values := expand_named(query, params)
+  return d.Exec(query, values...)
+}
+
+// Same api with `ExecQuery(...)` method except the query uses named 
+// placeholders, see `ExecNamed(...)`.
+func (d *DB) QueryNamed(query string, params map[string]interface{}) *sql.Rows {
+  query, values := expand_named(query, params)
+  return d.ExecQuery(query, values...)
+}
+
+// Replaces the named placeholders of a query and returns the values in the 
+// order of the positional placeholders.
+func expand_named(query string, params map[string]interface{}) (string, []interface{}) {
+  var result strings.Builder
+  var values []interface{}
+  var quote  byte
+
+  for i := 0; i < len(query); i++ {
+    ch := query[i]
+    switch {
+    case quote != 0:
+      result.WriteByte(ch)
+      if ch == '\\' && quote != '`' && i+1 < len(query) {
+        i++
+        result.WriteByte(query[i])
+      } else if ch == quote {
+        quote = 0
+      }
+    case ch == '\'' || ch == '"' || ch == '`':
+      quote = ch
+      result.WriteByte(ch)
+    case ch == '-' && strings.HasPrefix(query[i:], "-- "), ch == '#':
+      end := strings.IndexByte(query[i:], '\n')
+      if end < 0 { end = len(query) - i }
+      result.WriteString(query[i:i+end])
+      i += end - 1
+    case ch == '/' && strings.HasPrefix(query[i:], "/*"):
+      end := strings.Index(query[i:], "*/")
+      if end < 0 { end = len(query) - i } else { end += 2 }
+      result.WriteString(query[i:i+end])
+      i += end - 1
+    case ch == ':' && i+1 < len(query) && is_name_start(query[i+1]):
+      end := i + 2
+      for end < len(query) && is_name_char(query[end]) { end++ }
+      name := query[i+1:end]
+      value, ok := params[name]
+      if !ok { panic(fmt.Errorf("mysql: missing named parameter %q", name)) }
+      placeholders, args := expand_value(name, value)
+      result.WriteString(placeholders)
+      values = append(values, args...)
+      i = end - 1
+    default:
+      result.WriteByte(ch)
+    }
+  }
+  return result.String(), values
+}
+
+// Returns the placeholders of a value, one per element of a slice. Valuers 
+// are single values.
+func expand_value(name string, value interface{}) (string, []interface{}) {
+  if _, ok := value.([]byte); ok || value == nil {
+    return "?", []interface{}{value}
+  }
+  if _, ok := value.(driver.Valuer); ok { return "?", []interface{}{value} }
+  v := reflect.ValueOf(value)
+  if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+    return "?", []interface{}{value}
+  }
+  if v.Len() == 0 {
+    panic(fmt.Errorf("mysql: named parameter %q is an empty slice", name))
+  }
+  values := make([]interface{}, v.Len())
+  for i := range values { values[i] = v.Index(i).Interface() }
+  return strings.Repeat("?, ", len(values)-1) + "?", values
+}
+
+func is_name_start(ch byte) bool {
+  return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func is_name_char(ch byte) bool {
+  return is_name_start(ch) || ch >= '0' && ch <= '9'
+}
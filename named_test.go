@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandNamed(t *testing.T) {
+  params := map[string]interface{}{"id": 1, "ids": []int{1, 2}, "name": "alice"}
+  tests := []struct {
+    name   string
+    query  string
+    result string
+    values []interface{}
+  }{
+    {
+      "repeated names",
+      "SELECT * FROM users WHERE id = :id OR parent_id = :id",
+      "SELECT * FROM users WHERE id = ? OR parent_id = ?", []interface{}{1, 1},
+    },
+    {
+      "slice",
+      "SELECT * FROM users WHERE id IN (:ids) AND name = :name",
+      "SELECT * FROM users WHERE id IN (?, ?) AND name = ?", []interface{}{1, 2, "alice"},
+    },
+    {
+      "quotes",
+      "SELECT ':id', \"it\\\":s :id\", 'it''s :id', `:id` FROM users WHERE id = :id",
+      "SELECT ':id', \"it\\\":s :id\", 'it''s :id', `:id` FROM users WHERE id = ?",
+      []interface{}{1},
+    },
+    {
+      "backslash in quoted identifier",
+      "SELECT `a\\` FROM users WHERE id = :id",
+      "SELECT `a\\` FROM users WHERE id = ?", []interface{}{1},
+    },
+    {
+      "comments",
+      "/* it's :id */ SELECT * FROM users -- :id's\nWHERE id = :id # :name\n",
+      "/* it's :id */ SELECT * FROM users -- :id's\nWHERE id = ? # :name\n",
+      []interface{}{1},
+    },
+    {
+      "assignment",
+      "SELECT @total := COUNT(*) FROM users WHERE name = :name",
+      "SELECT @total := COUNT(*) FROM users WHERE name = ?", []interface{}{"alice"},
+    },
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      result, values := expand_named(test.query, params)
+      if result != test.result {
+        t.Errorf("expand_named(%q)\n got %q\nwant %q", test.query, result, test.result)
+      }
+      if !reflect.DeepEqual(values, test.values) {
+        t.Errorf("expand_named(%q) values = %#v, want %#v", test.query, values, test.values)
+      }
+    })
+  }
+}
+
+func TestExpandNamedInvalid(t *testing.T) {
+  tests := []struct {
+    name   string
+    query  string
+    params map[string]interface{}
+  }{
+    {"missing parameter", "SELECT * FROM users WHERE id = :id", nil},
+    {"empty slice", "SELECT * FROM users WHERE id IN (:ids)", map[string]interface{}{"ids": []int{}}},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      defer func() {
+        if recover() == nil { t.Errorf("expand_named(%q) didn't panic", test.query) }
+      }()
+      expand_named(test.query, test.params)
+    })
+  }
+}
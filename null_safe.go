@@ -0,0 +1,33 @@
+package mysql
+
+import "fmt"
+
+type null_safe_equal struct {
+  value interface{}
+}
+
+// Wraps a value which may be nil, so the condition is built with the NULL-safe 
+// equality operator `<=>`. It matches NULL columns when `value` is nil and 
+// behaves like `=` otherwise, so callers don't need different where maps for 
+// nil and non-nil values. Using the key operator `!=` negates the condition.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   var parent_id interface{} // nil or an id
+//   // SELECT * FROM `categories` WHERE `parent_id` <=> ?
+//   mysql.Select("categories", _json{"parent_id": mysql.EqOrNull(parent_id)})
+//
+// The same is possible with the key operator `{"parent_id <=>": parent_id}`.
+func EqOrNull(value interface{}) interface{} {
+  return null_safe_equal{value}
+}
+
+func (n null_safe_equal) condition(column, operator string) (string, []interface{}) {
+  values := []interface{}{n.value}
+  switch operator {
+  case "", "=", "<=>": return column + " <=> ?", values
+  case "!=", "<>":     return "NOT (" + column + " <=> ?)", values
+  }
+  panic(fmt.Errorf("mysql: operator %q can't be used with EqOrNull", operator))
+}
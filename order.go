@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Orders tables by their foreign keys, read from `INFORMATION_SCHEMA`, so 
+// every table comes after the tables it references. Inserting rows into the 
+// tables in this order, or deleting in reverse order, doesn't fail on foreign 
+// key constraints. Tables without dependencies between them keep their given 
+// order, and self-references are ignored.
+//
+// It panics when the foreign keys between the tables form a cycle.
+//
+// Example:
+//   mysql.SortTables("order_items", "orders", "users")
+//   // => []string{"users", "orders", "order_items"}
+func SortTables(tables ...string) []string {
+  var unique []string
+  for _, table := range tables {
+    if !contains_string(unique, table) { unique = append(unique, table) }
+  }
+  tables = unique
+
+  dependencies := make(map[string][]string, len(tables))
+  for _, table := range tables {
+    _, name := split_table(table)
+    for _, fk := range ForeignKeys(table) {
+      if fk.ReferencedTable == name { continue }
+      for _, other := range tables {
+        if _, other_name := split_table(other); other_name == fk.ReferencedTable {
+          dependencies[table] = append(dependencies[table], other)
+        }
+      }
+    }
+  }
+
+  sorted := make([]string, 0, len(tables))
+  placed := make(map[string]bool, len(tables))
+  for len(sorted) < len(tables) {
+    progress := false
+    for _, table := range tables {
+      if placed[table] { continue }
+      ready := true
+      for _, dependency := range dependencies[table] {
+        if !placed[dependency] {
+          ready = false
+          break
+        }
+      }
+      if !ready { continue }
+      sorted = append(sorted, table)
+      placed[table] = true
+      progress = true
+    }
+
+    if !progress {
+      var cycle []string
+      for _, table := range tables {
+        if !placed[table] { cycle = append(cycle, table) }
+      }
+      panic(fmt.Errorf(
+        "mysql: foreign keys between tables form a cycle: %s",
+        strings.Join(cycle, ", "),
+      ))
+    }
+  }
+  return sorted
+}
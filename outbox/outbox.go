@@ -0,0 +1,276 @@
+// Transactional outbox on the connection of package 
+// `github.com/je3f0o/go-jeefo-mysql`. Messages are inserted in the 
+// transaction of the business change by `Enqueue(...)`, and delivered to a 
+// `Publisher` by a `Consumer` afterwards, so events are published if and only 
+// if the change was committed.
+//
+// Outbox table:
+//   CREATE TABLE outbox (
+//     id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+//     topic        VARCHAR(255) NOT NULL,
+//     message_key  VARCHAR(255) NOT NULL DEFAULT '',
+//     payload      BLOB NOT NULL,
+//     attempts     INT UNSIGNED NOT NULL DEFAULT 0,
+//     last_error   TEXT NULL,
+//     available_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+//     delivered_at DATETIME(6) NULL,
+//     created_at   DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+//     KEY pending (delivered_at, available_at, id)
+//   );
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+// Default name of the outbox table.
+const DefaultTable = "outbox"
+
+// Message of the outbox.
+type Message struct {
+  ID        int64
+  Topic     string
+  // Partition or routing key, e.g. the id of the changed aggregate
+  Key       string
+  Payload   []byte
+  // Failed deliveries so far
+  Attempts  int
+  CreatedAt time.Time
+}
+
+// Delivers messages to a broker. The message ID is unique and stable across 
+// retries, so receivers can use it to drop duplicates. Kafka or NATS clients 
+// are adapted with a few lines, see `PublisherFunc`.
+type Publisher interface {
+  Publish(ctx context.Context, message *Message) error
+}
+
+// Adapts a function to a `Publisher`.
+//
+// Example:
+//   publisher := outbox.PublisherFunc(func(ctx context.Context, message *outbox.Message) error {
+//     return writer.WriteMessages(ctx, kafka.Message{
+//       Topic: message.Topic,
+//       Key:   []byte(message.Key),
+//       Value: message.Payload,
+//     })
+//   })
+type PublisherFunc func(ctx context.Context, message *Message) error
+
+func (f PublisherFunc) Publish(ctx context.Context, message *Message) error {
+  return f(ctx, message)
+}
+
+// Publishes messages as POST requests with the payload as body, and the 
+// headers "Outbox-Id", "Outbox-Topic" and "Outbox-Key". Responses with a 
+// status other than 2xx fail the delivery.
+type WebhookPublisher struct {
+  URL    string
+  Client *http.Client
+  // Content type of payloads, "application/json" when empty
+  ContentType string
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, message *Message) error {
+  request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(message.Payload))
+  if err != nil { return err }
+
+  content_type := p.ContentType
+  if content_type == "" { content_type = "application/json" }
+  request.Header.Set("Content-Type", content_type)
+  request.Header.Set("Outbox-Id", strconv.FormatInt(message.ID, 10))
+  request.Header.Set("Outbox-Topic", message.Topic)
+  request.Header.Set("Outbox-Key", message.Key)
+
+  client := p.Client
+  if client == nil { client = http.DefaultClient }
+  response, err := client.Do(request)
+  if err != nil { return err }
+  response.Body.Close()
+  if response.StatusCode < 200 || response.StatusCode > 299 {
+    return fmt.Errorf("outbox: webhook responded %s", response.Status)
+  }
+  return nil
+}
+
+// Inserts a message into the default outbox table, within the transaction 
+// carried by `ctx`. It panics like the queries of package mysql.
+//
+// Example:
+//   err := mysql.Transaction(ctx, func(ctx context.Context) error {
+//     id := CreateOrder(ctx, order)
+//     outbox.Enqueue(ctx, "orders.created", strconv.FormatInt(id, 10), payload)
+//     return nil
+//   })
+func Enqueue(ctx context.Context, topic, key string, payload []byte) int64 {
+  return EnqueueTo(ctx, DefaultTable, topic, key, payload)
+}
+
+// Same as `Enqueue(...)` with the name of the outbox table.
+func EnqueueTo(ctx context.Context, table, topic, key string, payload []byte) int64 {
+  result := mysql.WithContext(ctx).Insert(table, map[string]interface{}{
+    "topic":       topic,
+    "message_key": key,
+    "payload":     payload,
+  })
+  id, err := result.LastInsertId()
+  if err != nil { panic(err) }
+  return id
+}
+
+// Delivers messages of an outbox table with several workers. Workers claim 
+// batches of pending messages in a transaction with 
+// `FOR UPDATE SKIP LOCKED`, so any number of workers and processes share the 
+// outbox without delivering a message twice concurrently. Failed deliveries 
+// are retried with backoff, their state is stored in the outbox table.
+type Consumer struct {
+  Publisher Publisher
+  // Outbox table, `DefaultTable` when empty
+  Table string
+  // Number of workers, 1 when 0
+  Workers int
+  // Messages claimed per transaction, 100 when 0
+  BatchSize int
+  // Wait time when there are no pending messages, 1 second when 0
+  PollInterval time.Duration
+  // Deliveries of a message before it's given up, 0 retries forever
+  MaxAttempts int
+  // Delay before retrying a message, exponential from 1 second up to 10 
+  // minutes when nil
+  Backoff func(attempts int) time.Duration
+  // Called after a message was published, in the transaction marking it as 
+  // delivered, e.g. to record it for exactly-once processing downstream. 
+  // When it fails, the delivery is rolled back and retried.
+  Handoff func(ctx context.Context, message *Message) error
+}
+
+// Runs the workers until `ctx` is done. Errors of a batch are logged to 
+// `mysql.Logger` and the batch is retried after `PollInterval`.
+func (c *Consumer) Run(ctx context.Context) error {
+  workers := c.Workers
+  if workers < 1 { workers = 1 }
+
+  var wg sync.WaitGroup
+  for i := 0; i < workers; i++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      c.work(ctx)
+    }()
+  }
+  wg.Wait()
+  return ctx.Err()
+}
+
+// Delivers one batch of pending messages and returns the number of claimed 
+// messages.
+func (c *Consumer) Deliver(ctx context.Context) (claimed int, err error) {
+  err = mysql.WrapFunc(func() error {
+    return mysql.Transaction(ctx, func(ctx context.Context) error {
+      messages := c.claim(ctx)
+      claimed   = len(messages)
+      for _, message := range messages { c.deliver(ctx, message) }
+      return nil
+    })
+  })
+  return claimed, err
+}
+
+func (c *Consumer) work(ctx context.Context) {
+  interval := c.PollInterval
+  if interval <= 0 { interval = time.Second }
+
+  for ctx.Err() == nil {
+    claimed, err := c.Deliver(ctx)
+    if err != nil && ctx.Err() == nil { mysql.Logger.Println("outbox:", err) }
+    if claimed > 0 && err == nil { continue }
+
+    select {
+    case <-ctx.Done():
+    case <-time.After(interval):
+    }
+  }
+}
+
+func (c *Consumer) claim(ctx context.Context) []*Message {
+  batch_size := c.BatchSize
+  if batch_size < 1 { batch_size = 100 }
+
+  max_attempts := ""
+  if c.MaxAttempts > 0 { max_attempts = " AND `attempts` < " + strconv.Itoa(c.MaxAttempts) }
+  query := fmt.Sprintf(
+    "SELECT `id`, `topic`, `message_key`, `payload`, `attempts`, " +
+    "FLOOR(UNIX_TIMESTAMP(`created_at`) * 1000000) " +
+    "FROM %s WHERE `delivered_at` IS NULL AND `available_at` <= NOW(6)%s " +
+    "ORDER BY `id` LIMIT %d FOR UPDATE SKIP LOCKED;",
+    mysql.EscapeId(c.table()), max_attempts, batch_size,
+  )
+
+  rows := mysql.WithContext(ctx).ExecQuery(query)
+  defer rows.Close()
+  var messages []*Message
+  for rows.Next() {
+    message := &Message{}
+    var created_at int64
+    err := rows.Scan(
+      &message.ID, &message.Topic, &message.Key, &message.Payload,
+      &message.Attempts, &created_at,
+    )
+    if err != nil { panic(err) }
+    message.CreatedAt = time.UnixMicro(created_at)
+    messages = append(messages, message)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return messages
+}
+
+// Publishes a claimed message and stores the result of the delivery.
+func (c *Consumer) deliver(ctx context.Context, message *Message) {
+  handle := mysql.WithContext(ctx)
+  where  := map[string]interface{}{"id": message.ID}
+
+  err := c.Publisher.Publish(ctx, message)
+  if err == nil && c.Handoff != nil {
+    // Roll back the writes of a failed handoff only, the delivery is retried
+    handle.Exec("SAVEPOINT `outbox_handoff`;")
+    if err = c.Handoff(ctx, message); err != nil {
+      handle.Exec("ROLLBACK TO SAVEPOINT `outbox_handoff`;")
+    }
+  }
+  if err == nil {
+    handle.Update(c.table(), map[string]interface{}{
+      "delivered_at": mysql.Raw("NOW(6)"),
+      "last_error":   nil,
+    }, where)
+    return
+  }
+
+  message.Attempts++
+  delay := c.backoff(message.Attempts)
+  handle.Update(c.table(), map[string]interface{}{
+    "attempts":     message.Attempts,
+    "last_error":   err.Error(),
+    "available_at": mysql.Raw("NOW(6) + INTERVAL ? MICROSECOND", delay.Microseconds()),
+  }, where)
+}
+
+func (c *Consumer) backoff(attempts int) time.Duration {
+  if c.Backoff != nil { return c.Backoff(attempts) }
+  delay := time.Second
+  for i := 1; i < attempts && delay < 10 * time.Minute; i++ { delay *= 2 }
+  if delay > 10 * time.Minute { delay = 10 * time.Minute }
+  return delay
+}
+
+func (c *Consumer) table() string {
+  if c.Table == "" { return DefaultTable }
+  return c.Table
+}
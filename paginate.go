@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Name of the extra column used by `Paginate(...)` to count rows in the same 
+// query.
+const total_column = "__pagination_total"
+
+// A page of rows returned by `Paginate(...)`.
+type Pagination struct {
+  Rows       []map[string]interface{}
+  Total      int64
+  Page       int
+  PerPage    int
+  TotalPages int
+}
+
+// Retrieves a page of rows together with the total number of matching rows.
+//
+// Parameters:
+//   - `table`: same as `Select(...)`
+//   - `where`: same as `Select(...)`
+//   - `page`: page number starting from 1, smaller values are treated as 1
+//   - `per_page`: number of rows per page
+//   - `options`: same as `Select(...)` except `limit` and `offset` are 
+//                overridden. With `"single_query": true` the total is counted 
+//                by a `COUNT(*) OVER()` window function in the data query, 
+//                which requires MySQL 8.0 or MariaDB 10.2
+// Returns:
+//   - *Pagination: rows of the page, total rows and total pages
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   result := mysql.Paginate("products", _json{"active": 1}, page, 20, _json{
+//     "order": "created_at DESC",
+//   })
+//   // result.Rows, result.Total, result.TotalPages
+func (d *DB) Paginate(
+  table string,
+  where map[string]interface{},
+  page, per_page int,
+  args ...map[string]interface{},
+) *Pagination {
+  if per_page < 1 {
+    panic(fmt.Errorf("mysql: invalid number of rows per page %d", per_page))
+  }
+  if page < 1 { page = 1 }
+
+  options := map[string]interface{}{}
+  if len(args) > 0 {
+    for key, value := range args[0] { options[key] = value }
+  }
+  options = d.tenant_options(options)
+  options["limit"]  = per_page
+  options["offset"] = (page - 1) * per_page
+
+  result := &Pagination{Page: page, PerPage: per_page}
+  single, _ := options["single_query"].(bool)
+  if single {
+    columns := prepare_columns(options)
+    cols    := Raw(columns.query + ", COUNT(*) OVER() AS " + EscapeId(total_column), columns.values...)
+    query, values := build_select(cols, table, where, options, true)
+    rows := d.ExecQuery(query+";", values...)
+    defer rows.Close()
+    result.Rows = scan_maps(rows, options)
+    for _, row := range result.Rows {
+      result.Total = pagination_total(row[total_column])
+      delete(row, total_column)
+    }
+  }
+
+  // A page past the end has no rows to read the total from.
+  if !single || len(result.Rows) == 0 {
+    result.Total = d.count_select(table, where, options)
+    if !single { result.Rows = d.Select(table, where, options) }
+  }
+
+  result.TotalPages = int((result.Total + int64(per_page) - 1) / int64(per_page))
+  return result
+}
+
+// Counts the rows `Select(...)` returns without its order, limit and offset, 
+// so joins, groups and distinct rows are counted like they're selected.
+func (d *DB) count_select(table string, where, options map[string]interface{}) int64 {
+  // Distinct rows are counted on the selected columns.
+  cols := Raw("1")
+  if distinct, _ := options["distinct"].(bool); distinct { cols = prepare_columns(options) }
+  query, values := build_select(cols, table, where, options, false)
+  query = "SELECT COUNT(*) FROM (" + query + ") AS `pagination`;"
+
+  var count int64
+  if err := d.scan_row([]interface{}{&count}, query, values...); err != nil {
+    handle_error(err, query, values)
+  }
+  return count
+}
+
+// Returns the total of the window function of a row, whatever the options or 
+// a converter of `RegisterConverter(...)` turned it into.
+func pagination_total(value interface{}) int64 {
+  var text string
+  switch total := value.(type) {
+  case int64:  return total
+  case int:    return int64(total)
+  case uint64: return int64(total)
+  case string: text = total
+  case []byte: text = string(total)
+  default:
+    panic(fmt.Errorf("mysql: unexpected pagination total %v of type %T", value, value))
+  }
+  total, err := strconv.ParseInt(text, 10, 64)
+  if err != nil { panic(err) }
+  return total
+}
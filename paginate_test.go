@@ -0,0 +1,26 @@
+package mysql
+
+import "testing"
+
+func TestPaginationTotal(t *testing.T) {
+  tests := []struct {
+    value interface{}
+    total int64
+  }{
+    {int64(42), 42},
+    {42, 42},
+    {uint64(42), 42},
+    {"42", 42},
+    {[]byte("42"), 42},
+  }
+  for _, test := range tests {
+    if total := pagination_total(test.value); total != test.total {
+      t.Errorf("pagination_total(%#v) = %d, want %d", test.value, total, test.total)
+    }
+  }
+
+  defer func() {
+    if recover() == nil { t.Fatal("expected a panic for an unexpected total type") }
+  }()
+  pagination_total(42.0)
+}
@@ -0,0 +1,49 @@
+package mysql
+
+// Reads two columns of a table into a map, for the common id to name lookup 
+// pattern. Values are scanned directly into `K` and `V` without building a 
+// map per row, so the types must be scannable by `database/sql`. When several 
+// rows have the same key, the last one wins.
+//
+// Parameters:
+//   - `table`: name of the table
+//   - `key_column`: column of the map keys
+//   - `value_column`: column of the map values
+//   - `where`: conditions in the same format as `Select(...)`
+//   - `options`: options of `Select(...)` except columns
+//
+// Example:
+//   names := mysql.SelectPairs[int64, string]("users", "id", "name", _json{"active": 1})
+func SelectPairs[K comparable, V any](
+  table, key_column, value_column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) map[K]V {
+  return select_pairs[K, V](std, table, key_column, value_column, where, options...)
+}
+
+func select_pairs[K comparable, V any](
+  d *DB,
+  table, key_column, value_column string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) map[K]V {
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+  options = d.tenant_options(options)
+
+  cols := Raw(EscapeId(key_column) + ", " + EscapeId(value_column))
+  query, values := build_select(cols, table, where, options, true)
+  rows := d.ExecQuery(query+";", values...)
+  defer rows.Close()
+
+  pairs := map[K]V{}
+  for rows.Next() {
+    var key K
+    var value V
+    if err := rows.Scan(&key, &value); err != nil { panic(err) }
+    pairs[key] = value
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return pairs
+}
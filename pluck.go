@@ -0,0 +1,107 @@
+package mysql
+
+import "database/sql"
+
+// Returns a single column of the matching rows, for the common "ids of the 
+// rows matching X" query, without building a map per row. Values are strings 
+// like `Select(...)` values, or converted by the "typed", "null_as_nil" and 
+// "decode_json" options.
+//
+// Parameters:
+//   - `table`: name of the table
+//   - `column`: name of the column to return
+//   - `where`: conditions in the same format as `Select(...)`
+//   - `options`: options of `Select(...)` except columns
+//
+// Example:
+//   ids, err := mysql.Pluck("orders", "id", _json{"status": "pending"})
+func (d *DB) Pluck(
+  table, column string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) (values []interface{}, err error) {
+  defer RecoverError(&err)
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+
+  rows := d.pluck_rows(table, column, where, options)
+  defer rows.Close()
+
+  encrypted  := is_encrypted(table, column)
+  uuid       := is_uuid(table, column)
+  converters := value_converters(rows, options)
+  var raw   sql.RawBytes
+  var value interface{}
+  dest := interface{}(&raw)
+  if converters != nil { dest = &value }
+  for rows.Next() {
+    if err := rows.Scan(dest); err != nil { panic(err) }
+    if converters == nil { value = string(raw) } else { value = converters[0](value) }
+    if text, ok := value.(string); ok && encrypted && text != "" { value = decrypt(text) }
+    if uuid { value = uuid_text(value) }
+    values = append(values, value)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return values, nil
+}
+
+// Same with `Pluck(...)` except values are scanned directly into `T`, so the 
+// type must be scannable by `database/sql`, e.g. `int64` for ids or 
+// `sql.NullString` for nullable columns.
+//
+// Example:
+//   ids, err := mysql.PluckT[int64]("orders", "id", _json{"status": "pending"})
+func PluckT[T any](
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) ([]T, error) {
+  return pluck_typed[T](std, table, column, where, options...)
+}
+
+func pluck_typed[T any](
+  d *DB,
+  table, column string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) (values []T, err error) {
+  defer RecoverError(&err)
+  var options map[string]interface{}
+  if len(args) > 0 { options = args[0] }
+
+  rows := d.pluck_rows(table, column, where, options)
+  defer rows.Close()
+
+  encrypted := is_encrypted(table, column)
+  for rows.Next() {
+    var value T
+    if err := rows.Scan(&value); err != nil { panic(err) }
+    if text, ok := any(&value).(*string); ok && encrypted && *text != "" {
+      *text = decrypt(*text)
+    }
+    values = append(values, value)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return values, nil
+}
+
+func (d *DB) pluck_rows(
+  table, column string,
+  where, options map[string]interface{},
+) *sql.Rows {
+  options = d.select_options(options)
+  query, values := build_select(Raw(EscapeId(column)), table, where, options, true)
+  return d.ExecQuery(query+";", values...)
+}
+
+// Whether `column` is an encrypted column of the model of `table`.
+func is_encrypted(table, column string) bool {
+  model := ModelOf(table)
+  return model != nil && contains_string(model.Encrypted, column)
+}
+
+// Whether `column` is a UUID column of the model of `table`.
+func is_uuid(table, column string) bool {
+  model := ModelOf(table)
+  return model != nil && contains_string(model.UUID, column)
+}
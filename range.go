@@ -0,0 +1,49 @@
+package mysql
+
+import "fmt"
+
+// Range of values matched with `BETWEEN ? AND ?`, both bounds included. A nil 
+// bound leaves the range open on its side, so optional filters don't need 
+// different where maps. Using the key operator "NOT BETWEEN" negates the 
+// condition.
+//
+// The same is possible with a slice of two values and the key operator 
+// BETWEEN, `{"created_at BETWEEN": []interface{}{from, to}}`.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // SELECT * FROM `orders` WHERE `created_at` BETWEEN ? AND ?
+//   mysql.Select("orders", _json{"created_at": mysql.Range{From: from, To: to}})
+//
+//   // SELECT * FROM `products` WHERE `price` >= ?
+//   mysql.Select("products", _json{"price": mysql.Range{From: min_price}})
+type Range struct {
+  From interface{}
+  To   interface{}
+}
+
+func (r Range) condition(column, operator string) (string, []interface{}) {
+  not := false
+  switch operator {
+  case "", "=", "BETWEEN":
+  case "NOT BETWEEN":
+    not = true
+  default:
+    panic(fmt.Errorf("mysql: operator %q can't be used with Range", operator))
+  }
+
+  switch {
+  case r.From != nil && r.To != nil:
+    if not { operator = " NOT BETWEEN ? AND ?" } else { operator = " BETWEEN ? AND ?" }
+    return column + operator, []interface{}{r.From, r.To}
+  case r.From != nil:
+    if not { return column + " < ?", []interface{}{r.From} }
+    return column + " >= ?", []interface{}{r.From}
+  case r.To != nil:
+    if not { return column + " > ?", []interface{}{r.To} }
+    return column + " <= ?", []interface{}{r.To}
+  }
+  if not { return "1 = 0", nil }
+  return "1 = 1", nil
+}
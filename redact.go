@@ -0,0 +1,217 @@
+package mysql
+
+import (
+	"path"
+	"strings"
+)
+
+// Column name patterns of `path.Match`, matched case-insensitively, whose 
+// values are replaced by `Redacted` in `Debug` logs and in `Error.Values`. 
+// The column of a value is the one it's compared with or assigned to, e.g. 
+// `password` for "`password` = ?" or for its position in the column list of 
+// an INSERT. Set it to nil to log every value.
+//
+// Example:
+//   mysql.RedactColumns = append(mysql.RedactColumns, "*_ssn", "card_number")
+var RedactColumns = []string{"*password*", "*passwd*", "*secret*", "*token*"}
+
+// Optional hook masking the values of `Debug` logs and `Error.Values` which 
+// are not matched by `RedactColumns`. It's called with the column of the 
+// value, "" when it's unknown, and returns the value to log.
+//
+// Example:
+//   mysql.RedactValue = func(column string, value interface{}) interface{} {
+//     if text, ok := value.(string); ok && strings.Contains(text, "@") {
+//       return mysql.Redacted
+//     }
+//     return value
+//   }
+var RedactValue func(column string, value interface{}) interface{}
+
+// Replacement of redacted values.
+const Redacted = "[REDACTED]"
+
+// Returns a copy of `values` masked by `RedactColumns` and `RedactValue`, or 
+// `values` itself when no redaction is configured.
+func redact(query string, values []interface{}) []interface{} {
+  if len(values) == 0 || len(RedactColumns) == 0 && RedactValue == nil {
+    return values
+  }
+  columns := placeholder_columns(query)
+  result  := make([]interface{}, len(values))
+  for i, value := range values {
+    column := ""
+    if i < len(columns) { column = columns[i] }
+    switch {
+    case redacted_column(column): value = Redacted
+    case RedactValue != nil:      value = RedactValue(column, value)
+    }
+    result[i] = value
+  }
+  return result
+}
+
+func redacted_column(column string) bool {
+  if column == "" { return false }
+  column = strings.ToLower(column)
+  for _, pattern := range RedactColumns {
+    if matched, _ := path.Match(strings.ToLower(pattern), column); matched {
+      return true
+    }
+  }
+  return false
+}
+
+// Keywords ending the expression of a column, so a following placeholder 
+// like the one of "LIMIT ?" has no column.
+var expression_keywords = map[string]bool{
+  "SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true,
+  "XOR": true, "SET": true, "ON": true, "HAVING": true, "LIMIT": true,
+  "OFFSET": true, "UPDATE": true, "VALUES": true, "VALUE": true, "BY": true,
+}
+
+// Returns the column of each placeholder of the query, "" when it's unknown: 
+// the last column before the placeholder in its expression, the column of 
+// its position in the tuples of an INSERT, the operand of a CASE expression 
+// for the values after WHEN, or the column assigned a CASE expression for the 
+// values after THEN and ELSE.
+func placeholder_columns(query string) []string {
+  var (
+    columns  []string
+    insert   []string // column list of an INSERT or REPLACE
+    words    int
+    depth    int
+    position int    // position in a tuple of the VALUES of an INSERT
+    last     string // last column of the current expression
+    assigned string // column assigned a CASE expression
+    operand  string // column compared by the WHEN of a CASE expression
+    opened   bool   // reading the first word after CASE
+    between  bool
+    listing  bool   // reading the column list of an INSERT
+    tuples   bool   // reading the VALUES of an INSERT
+    inserted bool
+  )
+
+  word := func(text string, quoted bool) {
+    words++
+    if listing {
+      insert = append(insert, text)
+      return
+    }
+    first := opened
+    opened = false
+    if quoted {
+      last = text
+      if first { operand = text }
+      return
+    }
+    keyword := strings.ToUpper(text)
+    switch {
+    case words == 1 && (keyword == "INSERT" || keyword == "REPLACE"):
+      inserted = true
+    case keyword == "NOT", keyword == "IN", keyword == "LIKE", keyword == "IS",
+      keyword == "NULL", keyword == "ESCAPE", keyword == "REGEXP":
+      // operators keep the column
+    case keyword == "BETWEEN":
+      between = true
+    case keyword == "AND" && between:
+      between = false
+    case keyword == "CASE":
+      assigned, last, operand, opened = last, "", "", true
+    case keyword == "WHEN":
+      // the operand of "CASE `id` WHEN ?", none for "CASE WHEN `id` = ?"
+      last = operand
+    case keyword == "THEN", keyword == "ELSE":
+      last = assigned
+    case keyword == "END":
+      assigned, last, operand = "", "", ""
+    case (keyword == "VALUES" || keyword == "VALUE") && inserted && len(insert) > 0 && depth == 0:
+      tuples, last = true, ""
+    case keyword == "SELECT" && inserted:
+      // INSERT ... SELECT has no tuples
+      inserted, last = false, ""
+    case expression_keywords[keyword]:
+      last = ""
+      if tuples { tuples, inserted = false, false }
+    default:
+      last = text
+      if first { operand = text }
+    }
+  }
+
+  for i := 0; i < len(query); {
+    c := query[i]
+    switch {
+    case c == '\'' || c == '"':
+      i = skip_quoted(query, i)
+    case c == '/' && strings.HasPrefix(query[i:], "/*"):
+      // comments like query tags and optimizer hints, quotes and placeholders 
+      // in comments are ignored
+      end := strings.Index(query[i+2:], "*/")
+      if end < 0 { return columns }
+      i += end + 4
+    case c == '#' || c == '-' && strings.HasPrefix(query[i:], "-- "):
+      end := strings.IndexByte(query[i:], '\n')
+      if end < 0 { return columns }
+      i += end + 1
+    case c == '`':
+      end := skip_quoted(query, i)
+      if end - 1 > i + 1 {
+        word(strings.Replace(query[i+1:end-1], "``", "`", -1), true)
+      }
+      i = end
+    case c == '?':
+      column := last
+      if tuples && depth == 1 {
+        column = ""
+        if position < len(insert) { column = insert[position] }
+      }
+      columns = append(columns, column)
+      i++
+    case c == '(':
+      depth++
+      if inserted && !tuples && depth == 1 && len(insert) == 0 { listing = true }
+      if tuples && depth == 1 { position = 0 }
+      i++
+    case c == ')':
+      depth--
+      listing = false
+      i++
+    case c == ',':
+      if tuples && depth == 1 { position++ }
+      i++
+    case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80:
+      j := i
+      for j < len(query) && is_word_byte(query[j]) { j++ }
+      word(query[i:j], false)
+      i = j
+    default:
+      i++
+    }
+  }
+  return columns
+}
+
+// Returns the index after the quoted string or identifier starting at `i`, 
+// with doubled and escaped quotes.
+func skip_quoted(query string, i int) int {
+  quote := query[i]
+  for j := i + 1; j < len(query); j++ {
+    switch {
+    case query[j] == '\\' && quote != '`':
+      j++
+    case query[j] == quote:
+      if j+1 < len(query) && query[j+1] == quote {
+        j++
+        continue
+      }
+      return j + 1
+    }
+  }
+  return len(query)
+}
+
+func is_word_byte(c byte) bool {
+  return c == '_' || c == '$' || c >= '0' && c <= '9' ||
+    c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,110 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholderColumns(t *testing.T) {
+  tests := []struct {
+    name    string
+    query   string
+    columns []string
+  }{
+    {
+      "where",
+      "SELECT * FROM `users` WHERE `name` = ? AND `password` = ? LIMIT ?",
+      []string{"name", "password", ""},
+    },
+    {
+      "insert tuples",
+      "INSERT INTO `users` (`name`, `password`) VALUES (?, ?), (?, ?)",
+      []string{"name", "password", "name", "password"},
+    },
+    {
+      "insert with function values",
+      "INSERT INTO `users` (`created_at`, `password`) VALUES (NOW(), ?)",
+      []string{"password"},
+    },
+    {
+      "insert on duplicate key update",
+      "INSERT INTO `users` (`name`, `password`) VALUES (?, ?) " +
+        "ON DUPLICATE KEY UPDATE `token` = ?",
+      []string{"name", "password", "token"},
+    },
+    {
+      "insert select",
+      "INSERT INTO `archive` (`password`) SELECT `password` FROM `users` WHERE `id` = ?",
+      []string{"id"},
+    },
+    {
+      "update set",
+      "UPDATE `users` SET `password` = ?, `name` = ? WHERE `id` = ?",
+      []string{"password", "name", "id"},
+    },
+    {
+      "update many case",
+      "UPDATE `users` SET " +
+        "`password` = CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `password` END, " +
+        "`name` = CASE `id` WHEN ? THEN ? ELSE `name` END " +
+        "WHERE `id` IN (?, ?)",
+      []string{"id", "password", "id", "password", "id", "name", "id", "id"},
+    },
+    {
+      "searched case",
+      "UPDATE `users` SET `token` = CASE WHEN `name` = ? THEN ? ELSE ? END",
+      []string{"name", "token", "token"},
+    },
+    {
+      "between",
+      "SELECT * FROM `users` WHERE `secret` BETWEEN ? AND ? AND `name` = ?",
+      []string{"secret", "secret", "name"},
+    },
+    {
+      "quoted strings",
+      "SELECT * FROM `users` WHERE `name` = 'it''s ?' AND `note` = \"a \\\" ?\" " +
+        "AND `password` = ?",
+      []string{"password"},
+    },
+    {
+      "quoted identifier",
+      "SELECT * FROM `users` WHERE `pass``word` = ?",
+      []string{"pass`word"},
+    },
+    {
+      "block comment",
+      "/* app: users, don't log ? */ SELECT * FROM `users` WHERE `password` = ?",
+      []string{"password"},
+    },
+    {
+      "line comments",
+      "SELECT * FROM `users` -- don't log ?\nWHERE `password` = ? # it's ?\nAND `name` = ?",
+      []string{"password", "name"},
+    },
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      columns := placeholder_columns(test.query)
+      if !reflect.DeepEqual(columns, test.columns) {
+        t.Errorf("placeholder_columns(%q)\n got %q\nwant %q", test.query, columns, test.columns)
+      }
+    })
+  }
+}
+
+func TestRedact(t *testing.T) {
+  query  := "UPDATE `users` SET `password` = ?, `api_token` = ?, `name` = ? WHERE `id` = ?"
+  values := []interface{}{"hunter2", "abc", "alice", 1}
+
+  want := []interface{}{Redacted, Redacted, "alice", 1}
+  if result := redact(query, values); !reflect.DeepEqual(result, want) {
+    t.Errorf("redact() = %#v, want %#v", result, want)
+  }
+  if values[0] != "hunter2" { t.Error("redact() modified the values") }
+
+  defer func(columns []string) { RedactColumns = columns }(RedactColumns)
+  RedactColumns = nil
+  if result := redact(query, values); !reflect.DeepEqual(result, values) {
+    t.Errorf("redact() without RedactColumns = %#v, want %#v", result, values)
+  }
+}
@@ -0,0 +1,387 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fraction (0 to 1) of executed queries sampled into `QuerySampleRecorder`, 
+// 0 disables sampling.
+var QuerySampleRate float64 = 0
+
+// Receives sampled queries, see `SampleToFile(...)` and `SampleToTable(...)`.
+var QuerySampleRecorder func(sample *QuerySample)
+
+// Sampled query, a poor man's query digest for when pt-query-digest isn't 
+// available.
+type QuerySample struct {
+  // Query with literals replaced by "?", see `Fingerprint(...)`
+  Fingerprint string        `json:"fingerprint"`
+  // Example of the executed query
+  Query       string        `json:"query"`
+  Duration    time.Duration `json:"duration"`
+  // Rows read by the server, the delta of the session status counters 
+  // "Handler_read_%", or -1 when unknown. The reads of `SHOW STATUS` itself 
+  // are included, so small values are approximate.
+  RowsExamined int64     `json:"rows_examined"`
+  // Rows returned to the client
+  RowsSent     int64     `json:"rows_sent"`
+  Time         time.Time `json:"time"`
+  Error        string    `json:"error,omitempty"`
+}
+
+type no_sample_key struct{}
+
+var (
+  fingerprint_literal = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"|\b\d+(?:\.\d+)?(?:e[+-]?\d+)?\b|\b0x[0-9a-f]+\b`)
+  fingerprint_list    = regexp.MustCompile(`\b(in|values)\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+  fingerprint_space   = regexp.MustCompile(`\s+`)
+  // Comments like query tags, but not optimizer hints
+  fingerprint_comment = regexp.MustCompile(`/\*[^+][\s\S]*?\*/`)
+)
+
+// Normalizes a query to group similar queries, by replacing literals with "?" 
+// and collapsing value lists and whitespace.
+//
+// Example:
+//   mysql.Fingerprint("SELECT * FROM users WHERE id IN (1, 2) AND name = 'a'")
+//   // => "select * from users where id in(?+) and name = ?"
+func Fingerprint(query string) string {
+  var result strings.Builder
+  query = fingerprint_comment.ReplaceAllString(query, " ")
+  // Keep quoted identifiers as they are
+  for i, part := range strings.Split(query, "`") {
+    if i % 2 == 1 {
+      result.WriteString("`" + part + "`")
+      continue
+    }
+    part = fingerprint_literal.ReplaceAllString(strings.ToLower(part), "?")
+    part = fingerprint_list.ReplaceAllString(part, "$1(?+)")
+    result.WriteString(fingerprint_space.ReplaceAllString(part, " "))
+  }
+  return strings.TrimRight(strings.TrimSpace(result.String()), ";")
+}
+
+// Returns a `QuerySampleRecorder` which appends samples as JSON lines to a 
+// file.
+//
+// Example:
+//   recorder, err := mysql.SampleToFile("/var/log/app/queries.jsonl")
+//   if err != nil { log.Fatal(err) }
+//   mysql.QuerySampleRecorder = recorder
+//   mysql.QuerySampleRate     = 0.01
+func SampleToFile(path string) (func(sample *QuerySample), error) {
+  file, err := os.OpenFile(path, os.O_CREATE | os.O_APPEND | os.O_WRONLY, 0644)
+  if err != nil { return nil, err }
+
+  var mu sync.Mutex
+  encoder := json.NewEncoder(file)
+  return func(sample *QuerySample) {
+    mu.Lock()
+    defer mu.Unlock()
+    encoder.Encode(sample)
+  }, nil
+}
+
+// Returns a `QuerySampleRecorder` which inserts samples into a table of the 
+// default connection in the background. Inserts of samples are not sampled.
+//
+// Example table:
+//   CREATE TABLE query_samples (
+//     id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+//     fingerprint   TEXT NOT NULL,
+//     query         TEXT NOT NULL,
+//     duration_us   BIGINT NOT NULL,
+//     rows_examined BIGINT NOT NULL,
+//     rows_sent     BIGINT NOT NULL,
+//     error         TEXT NULL,
+//     created_at    DATETIME(6) NOT NULL
+//   );
+func SampleToTable(table string) func(sample *QuerySample) {
+  handle := std.WithContext(context.WithValue(context.Background(), no_sample_key{}, true))
+  return func(sample *QuerySample) {
+    go func() {
+      defer func() {
+        if err := recover(); err != nil { Logger.Println("query sample:", err) }
+      }()
+      var query_error interface{}
+      if sample.Error != "" { query_error = sample.Error }
+      handle.Insert(table, map[string]interface{}{
+        "fingerprint":   sample.Fingerprint,
+        "query":         sample.Query,
+        "duration_us":   sample.Duration.Microseconds(),
+        "rows_examined": sample.RowsExamined,
+        "rows_sent":     sample.RowsSent,
+        "error":         query_error,
+        "created_at":    sample.Time,
+      })
+    }()
+  }
+}
+
+// Connector wrapping driver connections, so sampled queries are measured on 
+// the connection which runs them.
+type sampling_connector struct {
+  driver.Connector
+}
+
+type sampling_conn struct {
+  driver.Conn
+}
+
+type sampling_stmt struct {
+  driver.Stmt
+  conn  *sampling_conn
+  query string
+}
+
+type sampling_rows struct {
+  driver.Rows
+  finish func(rows_sent int64, err error)
+  sent   int64
+}
+
+func (c sampling_connector) Connect(ctx context.Context) (driver.Conn, error) {
+  conn, err := c.Connector.Connect(ctx)
+  if err != nil { return nil, err }
+  return &sampling_conn{conn}, nil
+}
+
+func (c *sampling_conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+  return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *sampling_conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+  var stmt driver.Stmt
+  var err error
+  if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+    stmt, err = preparer.PrepareContext(ctx, query)
+  } else {
+    stmt, err = c.Conn.Prepare(query)
+  }
+  if err != nil { return nil, err }
+  return &sampling_stmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *sampling_conn) ExecContext(
+  ctx context.Context,
+  query string,
+  args []driver.NamedValue,
+) (driver.Result, error) {
+  execer, ok := c.Conn.(driver.ExecerContext)
+  if !ok { return nil, driver.ErrSkip }
+
+  finish := c.start(ctx, query)
+  result, err := execer.ExecContext(ctx, query, args)
+  if finish != nil && err != driver.ErrSkip { finish(0, err) }
+  return result, err
+}
+
+func (c *sampling_conn) QueryContext(
+  ctx context.Context,
+  query string,
+  args []driver.NamedValue,
+) (driver.Rows, error) {
+  queryer, ok := c.Conn.(driver.QueryerContext)
+  if !ok { return nil, driver.ErrSkip }
+
+  finish := c.start(ctx, query)
+  rows, err := queryer.QueryContext(ctx, query, args)
+  return sampled_rows(rows, err, finish)
+}
+
+func (c *sampling_conn) Ping(ctx context.Context) error {
+  if pinger, ok := c.Conn.(driver.Pinger); ok { return pinger.Ping(ctx) }
+  return nil
+}
+
+func (c *sampling_conn) ResetSession(ctx context.Context) error {
+  if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+    return resetter.ResetSession(ctx)
+  }
+  return nil
+}
+
+func (c *sampling_conn) IsValid() bool {
+  if validator, ok := c.Conn.(driver.Validator); ok { return validator.IsValid() }
+  return true
+}
+
+func (c *sampling_conn) CheckNamedValue(value *driver.NamedValue) error {
+  if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+    return checker.CheckNamedValue(value)
+  }
+  return driver.ErrSkip
+}
+
+// Reads the status counters when the query is sampled and returns a function 
+// recording the sample, otherwise nil.
+func (c *sampling_conn) start(ctx context.Context, query string) func(int64, error) {
+  if QuerySampleRate <= 0 || QuerySampleRecorder == nil { return nil }
+  if skip, _ := ctx.Value(no_sample_key{}).(bool); skip { return nil }
+  if rand.Float64() >= QuerySampleRate { return nil }
+
+  before := c.rows_examined(ctx)
+  start  := time.Now()
+  return func(rows_sent int64, err error) {
+    sample := &QuerySample{
+      Fingerprint:  Fingerprint(query),
+      Query:        query,
+      Duration:     time.Since(start),
+      RowsExamined: -1,
+      RowsSent:     rows_sent,
+      Time:         start,
+    }
+    if err != nil { sample.Error = err.Error() }
+    if after := c.rows_examined(ctx); before >= 0 && after >= before {
+      sample.RowsExamined = after - before
+    }
+    QuerySampleRecorder(sample)
+  }
+}
+
+// Returns the sum of the session status counters "Handler_read_%", or -1.
+func (c *sampling_conn) rows_examined(ctx context.Context) int64 {
+  queryer, ok := c.Conn.(driver.QueryerContext)
+  if !ok { return -1 }
+  rows, err := queryer.QueryContext(ctx, "SHOW SESSION STATUS LIKE 'Handler_read%';", nil)
+  if err != nil { return -1 }
+  defer rows.Close()
+
+  var total int64
+  row := make([]driver.Value, len(rows.Columns()))
+  for rows.Next(row) == nil {
+    var value string
+    switch v := row[len(row)-1].(type) {
+    case []byte: value = string(v)
+    case string: value = v
+    }
+    n, _ := strconv.ParseInt(value, 10, 64)
+    total += n
+  }
+  return total
+}
+
+func (s *sampling_stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+  finish := s.conn.start(ctx, s.query)
+  var result driver.Result
+  var err error
+  if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+    result, err = execer.ExecContext(ctx, args)
+  } else {
+    result, err = s.Stmt.Exec(named_values(args))
+  }
+  if finish != nil { finish(0, err) }
+  return result, err
+}
+
+func (s *sampling_stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+  finish := s.conn.start(ctx, s.query)
+  var rows driver.Rows
+  var err error
+  if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+    rows, err = queryer.QueryContext(ctx, args)
+  } else {
+    rows, err = s.Stmt.Query(named_values(args))
+  }
+  return sampled_rows(rows, err, finish)
+}
+
+func (s *sampling_stmt) CheckNamedValue(value *driver.NamedValue) error {
+  if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+    return checker.CheckNamedValue(value)
+  }
+  return s.conn.CheckNamedValue(value)
+}
+
+func (s *sampling_stmt) ColumnConverter(i int) driver.ValueConverter {
+  if converter, ok := s.Stmt.(driver.ColumnConverter); ok {
+    return converter.ColumnConverter(i)
+  }
+  return driver.DefaultParameterConverter
+}
+
+func named_values(args []driver.NamedValue) []driver.Value {
+  values := make([]driver.Value, len(args))
+  for i, arg := range args { values[i] = arg.Value }
+  return values
+}
+
+// Wraps rows of a sampled query, which is recorded when the rows are closed.
+func sampled_rows(rows driver.Rows, err error, finish func(int64, error)) (driver.Rows, error) {
+  if finish == nil || err == driver.ErrSkip { return rows, err }
+  if err != nil {
+    finish(0, err)
+    return nil, err
+  }
+  return &sampling_rows{Rows: rows, finish: finish}, nil
+}
+
+func (r *sampling_rows) Next(dest []driver.Value) error {
+  err := r.Rows.Next(dest)
+  if err == nil { r.sent++ }
+  return err
+}
+
+func (r *sampling_rows) Close() error {
+  err := r.Rows.Close()
+  if r.finish != nil {
+    r.finish(r.sent, err)
+    r.finish = nil
+  }
+  return err
+}
+
+func (r *sampling_rows) HasNextResultSet() bool {
+  rows, ok := r.Rows.(driver.RowsNextResultSet)
+  return ok && rows.HasNextResultSet()
+}
+
+func (r *sampling_rows) NextResultSet() error {
+  if rows, ok := r.Rows.(driver.RowsNextResultSet); ok { return rows.NextResultSet() }
+  return driver.ErrSkip
+}
+
+func (r *sampling_rows) ColumnTypeScanType(i int) reflect.Type {
+  if rows, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+    return rows.ColumnTypeScanType(i)
+  }
+  return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *sampling_rows) ColumnTypeDatabaseTypeName(i int) string {
+  if rows, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+    return rows.ColumnTypeDatabaseTypeName(i)
+  }
+  return ""
+}
+
+func (r *sampling_rows) ColumnTypeNullable(i int) (bool, bool) {
+  if rows, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+    return rows.ColumnTypeNullable(i)
+  }
+  return false, false
+}
+
+func (r *sampling_rows) ColumnTypePrecisionScale(i int) (int64, int64, bool) {
+  if rows, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+    return rows.ColumnTypePrecisionScale(i)
+  }
+  return 0, 0, false
+}
+
+func (r *sampling_rows) ColumnTypeLength(i int) (int64, bool) {
+  if rows, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+    return rows.ColumnTypeLength(i)
+  }
+  return 0, false
+}
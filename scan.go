@@ -0,0 +1,184 @@
+package mysql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+)
+
+// Set to `true` to return values of result maps as Go types matching the 
+// column types, instead of strings. A query can opt in or out with the option 
+// `"typed": bool`. Typed values are:
+//   - `int64` for integer columns, `uint64` for BIGINT UNSIGNED
+//   - `float64` for FLOAT and DOUBLE
+//   - `time.Time` for DATE, DATETIME and TIMESTAMP, in UTC unless 
+//     `Config.ParseTime` is enabled, then in `Config.Location`
+//   - `bool` for BIT values of one byte like BIT(1), `[]byte` for wider BIT 
+//     and binary columns
+//   - `string` for text columns, and DECIMAL and TIME to keep their precision
+//   - `nil` for NULL
+var TypedValues = false
+
+// Set to `true` to return NULL values of result maps as `nil` instead of "", 
+// so they can be told apart from empty strings. A query can opt in or out 
+// with the option `"null_as_nil": bool`. Typed values always return `nil`.
+var NullAsNil = false
+
+func typed_values(options map[string]interface{}) bool {
+  if typed, ok := options["typed"].(bool); ok { return typed }
+  return TypedValues
+}
+
+func null_as_nil(options map[string]interface{}) bool {
+  if nulls, ok := options["null_as_nil"].(bool); ok { return nulls }
+  return NullAsNil
+}
+
+// Set to `true` to decode values of JSON columns in result maps into 
+// `map[string]interface{}`, `[]interface{}` or scalars, like 
+// `encoding/json` does. A query can opt in or out with the option 
+// `"decode_json": bool`. Columns are detected by their type, other columns 
+// holding JSON, e.g. on MariaDB which stores JSON as LONGTEXT, are listed with 
+// the option `"json": []string`.
+var DecodeJSON = false
+
+func decode_json(options map[string]interface{}) bool {
+  if decode, ok := options["decode_json"].(bool); ok { return decode }
+  return DecodeJSON
+}
+
+// Returns the functions converting values of the driver into the values of 
+// result maps, or nil when values are scanned as strings with NULL as "". 
+// Decoders of `RegisterConverter(...)` apply to every query.
+func value_converters(rows *sql.Rows, options map[string]interface{}) []func(interface{}) interface{} {
+  typed  := typed_values(options)
+  nulls  := typed || null_as_nil(options)
+  decode := decode_json(options)
+  json_columns, _ := options["json"].([]string)
+  decoders := column_decoders()
+  if !nulls && !decode && len(json_columns) == 0 && decoders == nil { return nil }
+
+  types, err := rows.ColumnTypes()
+  if err != nil { panic(err) }
+  converters := make([]func(interface{}) interface{}, len(types))
+  for i, column := range types {
+    type_name := column.DatabaseTypeName()
+    switch {
+    case decode && type_name == "JSON", contains_string(json_columns, column.Name()):
+      converters[i] = typed_json
+    case decoders[type_name] != nil:
+      converters[i] = decoded(decoders[type_name])
+    case typed:
+      converters[i] = typed_converter(type_name)
+    default:
+      converters[i] = typed_string
+    }
+    if !nulls { converters[i] = null_as_empty(converters[i]) }
+  }
+  return converters
+}
+
+func null_as_empty(converter func(interface{}) interface{}) func(interface{}) interface{} {
+  return func(value interface{}) interface{} {
+    if value == nil { return "" }
+    return converter(value)
+  }
+}
+
+func typed_converter(type_name string) func(interface{}) interface{} {
+  switch type_name {
+  case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR",
+       "UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT":
+    return typed_int
+  case "UNSIGNED BIGINT":
+    return typed_uint
+  case "FLOAT", "DOUBLE":
+    return typed_float
+  case "DATE", "DATETIME", "TIMESTAMP":
+    return typed_time
+  case "BIT":
+    return typed_bit
+  case "BINARY", "VARBINARY", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY":
+    return typed_bytes
+  }
+  return typed_string
+}
+
+func typed_int(value interface{}) interface{} {
+  switch value := value.(type) {
+  case []byte:
+    i, err := strconv.ParseInt(string(value), 10, 64)
+    if err != nil { panic(err) }
+    return i
+  case uint64:
+    return int64(value)
+  }
+  return value
+}
+
+func typed_uint(value interface{}) interface{} {
+  switch value := value.(type) {
+  case []byte:
+    i, err := strconv.ParseUint(string(value), 10, 64)
+    if err != nil { panic(err) }
+    return i
+  case int64:
+    return uint64(value)
+  }
+  return value
+}
+
+func typed_float(value interface{}) interface{} {
+  switch value := value.(type) {
+  case []byte:
+    f, err := strconv.ParseFloat(string(value), 64)
+    if err != nil { panic(err) }
+    return f
+  case float32:
+    return float64(value)
+  }
+  return value
+}
+
+func typed_time(value interface{}) interface{} {
+  bytes, ok := value.([]byte)
+  if !ok { return value }
+  if t, ok := parse_datetime(string(bytes)); ok { return t }
+  return string(bytes)
+}
+
+func typed_bit(value interface{}) interface{} {
+  if bytes, ok := value.([]byte); ok && len(bytes) == 1 {
+    return bytes[0] != 0
+  }
+  return typed_bytes(value)
+}
+
+func typed_json(value interface{}) interface{} {
+  var data []byte
+  switch value := value.(type) {
+  case []byte: data = value
+  case string: data = []byte(value)
+  default:     return value
+  }
+  var decoded interface{}
+  if err := json.Unmarshal(data, &decoded); err != nil { panic(err) }
+  return decoded
+}
+
+// Bytes scanned into an interface are already copied by `database/sql`.
+func typed_bytes(value interface{}) interface{} {
+  return value
+}
+
+func typed_string(value interface{}) interface{} {
+  switch value := value.(type) {
+  case []byte:
+    return string(value)
+  case int64:
+    return strconv.FormatInt(value, 10)
+  case float64:
+    return strconv.FormatFloat(value, 'f', -1, 64)
+  }
+  return value
+}
@@ -0,0 +1,375 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Column definition read from `INFORMATION_SCHEMA.COLUMNS`.
+type Column struct {
+  Name       string
+  Position   int
+  DataType   string // e.g. "varchar"
+  ColumnType string // e.g. "varchar(255)", "int unsigned", "enum('a','b')"
+  Nullable   bool
+  Default    sql.NullString
+  Key        string // "PRI", "UNI", "MUL" or empty
+  Extra      string // e.g. "auto_increment"
+  MaxLength  int64  // maximum length in characters for string types
+  Precision  int64
+  Scale      int64
+}
+
+// Index read from `INFORMATION_SCHEMA.STATISTICS`.
+type Index struct {
+  Name    string
+  Unique  bool
+  Columns []string // ordered by their position in the index
+}
+
+// Foreign key column read from `INFORMATION_SCHEMA.KEY_COLUMN_USAGE`.
+type ForeignKey struct {
+  Name             string
+  Column           string
+  ReferencedTable  string
+  ReferencedColumn string
+}
+
+// Reports whether the column value is generated by the server, either 
+// `AUTO_INCREMENT` or a generated column.
+func (c *Column) IsGenerated() bool {
+  extra := strings.ToLower(c.Extra)
+  return strings.Contains(extra, "auto_increment") ||
+         strings.Contains(extra, "generated")
+}
+
+// Reports whether the column type is `UNSIGNED`.
+func (c *Column) IsUnsigned() bool {
+  return strings.Contains(strings.ToLower(c.ColumnType), "unsigned")
+}
+
+// Returns allowed values of an `ENUM` or `SET` column, otherwise nil.
+func (c *Column) EnumValues() []string {
+  return enum_values(c.ColumnType)
+}
+
+// Duration after which cached table definitions are read again. When 0 they 
+// are cached until `InvalidateTable(...)`, `ResetSchemaCache()` or 
+// `Reload(...)` is called.
+var SchemaCacheTTL time.Duration = 0
+
+// Statistics of the schema cache, see `SchemaCacheStats()`.
+type SchemaCacheStatistics struct {
+  // Lookups served from the cache
+  Hits int64 `json:"hits"`
+  // Lookups which read the definitions from the server
+  Misses int64 `json:"misses"`
+  // Misses caused by an expired entry
+  Expirations int64 `json:"expirations"`
+  // Number of cached tables
+  Tables int `json:"tables"`
+}
+
+type schema_entry struct {
+  columns      []Column
+  indexes      []Index
+  foreign_keys []ForeignKey
+  loaded_at    time.Time
+}
+
+type schema_load struct {
+  done  chan struct{}
+  entry *schema_entry
+  err   interface{}
+}
+
+// Key of the schema cache, definitions are read on the pool of a handle, so 
+// named connections of `Connect(...)` get the tables of their own database.
+type schema_key struct {
+  pool  *atomic.Pointer[sql.DB]
+  table string
+}
+
+// Cached table definitions by pool and table name, shared by every schema 
+// dependent feature. Concurrent misses of a table wait for a single read in 
+// `schema_loads`.
+var (
+  schema_cache      sync.Map
+  schema_loads      sync.Map
+  schema_generation atomic.Int64
+  schema_stats      struct{ hits, misses, expirations atomic.Int64 }
+)
+
+// Reads the column definitions of a table in the current database, or in the 
+// given database when `table` is qualified like "db.table". Definitions are 
+// cached per connection pool, see `SchemaCacheTTL`.
+//
+// Returns:
+//   - []Column: columns ordered by their position in the table
+func (d *DB) Columns(table string) []Column {
+  return d.cached_schema(table).columns
+}
+
+// Reads the foreign keys of a table in the current database, or in the given 
+// database when `table` is qualified like "db.table". Foreign keys are cached 
+// together with `Columns(...)`.
+func (d *DB) ForeignKeys(table string) []ForeignKey {
+  return d.cached_schema(table).foreign_keys
+}
+
+// Reads the indexes of a table in the current database, or in the given 
+// database when `table` is qualified like "db.table". Indexes are cached 
+// together with `Columns(...)`.
+func (d *DB) Indexes(table string) []Index {
+  return d.cached_schema(table).indexes
+}
+
+// Drops every cached table definition, for example after running migrations.
+func ResetSchemaCache() { reset_schema_cache() }
+
+// Drops the cached definitions of a table of every connection, for example 
+// after altering it.
+func InvalidateTable(table string) {
+  schema_generation.Add(1)
+  schema_cache.Range(func(key, _ interface{}) bool {
+    if key.(schema_key).table == table { schema_cache.Delete(key) }
+    return true
+  })
+}
+
+// Reads the definitions of the given tables into the schema cache, or of 
+// every table of the current database when none is given, so the first 
+// requests after startup don't wait for them.
+//
+// Example:
+//   mysql.Init(cfg)
+//   if err := mysql.WarmSchemaCache(); err != nil { log.Fatal(err) }
+func (d *DB) WarmSchemaCache(tables ...string) (err error) {
+  defer RecoverError(&err)
+  if len(tables) == 0 { tables = d.schema_reader().table_names() }
+  for _, table := range tables { d.cached_schema(table) }
+  return nil
+}
+
+// Returns the names of the tables of the current database.
+func (d *DB) table_names() []string {
+  query := "SELECT TABLE_NAME FROM `INFORMATION_SCHEMA`.`TABLES` " +
+    "WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME;"
+  rows := d.ExecQuery(query)
+  defer rows.Close()
+  var tables []string
+  for rows.Next() {
+    var table string
+    if err := rows.Scan(&table); err != nil { panic(err) }
+    tables = append(tables, table)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return tables
+}
+
+// Returns statistics of the schema cache.
+func SchemaCacheStats() SchemaCacheStatistics {
+  stats := SchemaCacheStatistics{
+    Hits:        schema_stats.hits.Load(),
+    Misses:      schema_stats.misses.Load(),
+    Expirations: schema_stats.expirations.Load(),
+  }
+  schema_cache.Range(func(_, _ interface{}) bool {
+    stats.Tables++
+    return true
+  })
+  return stats
+}
+
+// Returns the handle reading table definitions, on the pool of `d` rather 
+// than its transaction or session.
+func (d *DB) schema_reader() *DB {
+  return &DB{pool: d.pool, ctx: d.ctx}
+}
+
+func (d *DB) cached_schema(table string) *schema_entry {
+  key := schema_key{d.pool, table}
+  if value, ok := schema_cache.Load(key); ok {
+    entry := value.(*schema_entry)
+    if SchemaCacheTTL <= 0 || time.Since(entry.loaded_at) < SchemaCacheTTL {
+      schema_stats.hits.Add(1)
+      return entry
+    }
+    schema_stats.expirations.Add(1)
+  }
+  schema_stats.misses.Add(1)
+
+  load := &schema_load{done: make(chan struct{})}
+  if value, loading := schema_loads.LoadOrStore(key, load); loading {
+    load = value.(*schema_load)
+    <-load.done
+    if load.err != nil { panic(load.err) }
+    return load.entry
+  }
+  defer func() {
+    if err := recover(); err != nil { load.err = err }
+    schema_loads.Delete(key)
+    close(load.done)
+    if load.err != nil { panic(load.err) }
+  }()
+
+  generation := schema_generation.Load()
+  reader     := d.schema_reader()
+  load.entry = &schema_entry{
+    columns:      reader.read_columns(table),
+    indexes:      reader.read_indexes(table),
+    foreign_keys: reader.read_foreign_keys(table),
+    loaded_at:    time.Now(),
+  }
+  // Definitions read while the cache was invalidated may be outdated
+  if generation == schema_generation.Load() {
+    schema_cache.Store(key, load.entry)
+  }
+  return load.entry
+}
+
+func reset_schema_cache() {
+  schema_generation.Add(1)
+  schema_cache.Range(func(key, _ interface{}) bool {
+    schema_cache.Delete(key)
+    return true
+  })
+}
+
+// Returns the cached tables of the default connection.
+func schema_cache_state() map[string]interface{} {
+  state := map[string]interface{}{}
+  schema_cache.Range(func(key, value interface{}) bool {
+    if key.(schema_key).pool != std.pool { return true }
+    entry := value.(*schema_entry)
+    state[key.(schema_key).table] = map[string]interface{}{
+      "columns":      len(entry.columns),
+      "indexes":      len(entry.indexes),
+      "foreign_keys": len(entry.foreign_keys),
+      "loaded_at":    entry.loaded_at,
+    }
+    return true
+  })
+  return state
+}
+
+func (d *DB) read_columns(table string) []Column {
+  where, values := schema_where(table)
+  query := "SELECT COLUMN_NAME, ORDINAL_POSITION, DATA_TYPE, COLUMN_TYPE, " +
+    "IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY, EXTRA, " +
+    "IFNULL(CHARACTER_MAXIMUM_LENGTH, 0), IFNULL(NUMERIC_PRECISION, 0), " +
+    "IFNULL(NUMERIC_SCALE, 0) FROM `INFORMATION_SCHEMA`.`COLUMNS` " +
+    "WHERE " + where + " " +
+    "ORDER BY ORDINAL_POSITION;"
+  rows := d.ExecQuery(query, values...)
+  defer rows.Close()
+
+  var columns []Column
+  for rows.Next() {
+    var c Column
+    var nullable string
+    err := rows.Scan(
+      &c.Name, &c.Position, &c.DataType, &c.ColumnType, &nullable,
+      &c.Default, &c.Key, &c.Extra, &c.MaxLength, &c.Precision, &c.Scale,
+    )
+    if err != nil { panic(err) }
+    c.Nullable = nullable == "YES"
+    columns = append(columns, c)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return columns
+}
+
+func (d *DB) read_indexes(table string) []Index {
+  where, values := schema_where(table)
+  query := "SELECT INDEX_NAME, NON_UNIQUE, COLUMN_NAME " +
+    "FROM `INFORMATION_SCHEMA`.`STATISTICS` " +
+    "WHERE " + where + " " +
+    "ORDER BY INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX;"
+  rows := d.ExecQuery(query, values...)
+  defer rows.Close()
+
+  var indexes []Index
+  for rows.Next() {
+    var index_name, column string
+    var non_unique int
+    if err := rows.Scan(&index_name, &non_unique, &column); err != nil {
+      panic(err)
+    }
+    last := len(indexes) - 1
+    if last < 0 || indexes[last].Name != index_name {
+      indexes = append(indexes, Index{Name: index_name, Unique: non_unique == 0})
+      last++
+    }
+    indexes[last].Columns = append(indexes[last].Columns, column)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return indexes
+}
+
+func (d *DB) read_foreign_keys(table string) []ForeignKey {
+  where, values := schema_where(table)
+  query := "SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, " +
+    "REFERENCED_COLUMN_NAME FROM `INFORMATION_SCHEMA`.`KEY_COLUMN_USAGE` " +
+    "WHERE " + where + " " +
+    "AND REFERENCED_TABLE_NAME IS NOT NULL ORDER BY ORDINAL_POSITION;"
+  rows := d.ExecQuery(query, values...)
+  defer rows.Close()
+
+  var keys []ForeignKey
+  for rows.Next() {
+    var fk ForeignKey
+    err := rows.Scan(&fk.Name, &fk.Column, &fk.ReferencedTable, &fk.ReferencedColumn)
+    if err != nil { panic(err) }
+    keys = append(keys, fk)
+  }
+  if err := rows.Err(); err != nil { panic(err) }
+  return keys
+}
+
+// Splits a table like "shop.orders" into its database and name, the database 
+// is "" when the table isn't qualified.
+func split_table(table string) (string, string) {
+  if schema, name, found := strings.Cut(table, "."); found { return schema, name }
+  return "", table
+}
+
+// Returns the INFORMATION_SCHEMA condition of a table and its values, in the 
+// current database when the table isn't qualified.
+func schema_where(table string) (string, []interface{}) {
+  schema, name := split_table(table)
+  if schema == "" { return "TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", []interface{}{name} }
+  return "TABLE_SCHEMA = ? AND TABLE_NAME = ?", []interface{}{schema, name}
+}
+
+func enum_values(column_type string) []string {
+  lower := strings.ToLower(column_type)
+  if !strings.HasPrefix(lower, "enum(") && !strings.HasPrefix(lower, "set(") {
+    return nil
+  }
+
+  list := column_type[strings.Index(column_type, "(")+1 : strings.LastIndex(column_type, ")")]
+  var values []string
+  var value strings.Builder
+  quoted := false
+  for i := 0; i < len(list); i++ {
+    ch := list[i]
+    switch {
+    case ch == '\'' && quoted && i+1 < len(list) && list[i+1] == '\'':
+      value.WriteByte('\'')
+      i++
+    case ch == '\'':
+      quoted = !quoted
+      if !quoted {
+        values = append(values, value.String())
+        value.Reset()
+      }
+    case quoted:
+      value.WriteByte(ch)
+    }
+  }
+  return values
+}
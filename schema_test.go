@@ -0,0 +1,103 @@
+package mysql_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestSchemaQueryValues(t *testing.T) {
+  tests := []struct {
+    table     string
+    condition string
+    values    []interface{}
+  }{
+    {"orders", "TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", []interface{}{"orders"}},
+    {`shop\'.orders`, "TABLE_SCHEMA = ? AND TABLE_NAME = ?", []interface{}{`shop\'`, "orders"}},
+  }
+
+  for _, test := range tests {
+    t.Run(test.table, func(t *testing.T) {
+      mock := mysqltest.New(t)
+      mysql.ResetSchemaCache()
+      mysql.Columns(test.table)
+
+      // Columns, indexes and foreign keys
+      queries := mock.Queries()
+      if len(queries) != 3 { t.Fatalf("queries = %v, want 3 queries", queries) }
+      for _, query := range queries {
+        if !strings.Contains(query.SQL, test.condition) {
+          t.Fatalf("query %q doesn't contain %q", query.SQL, test.condition)
+        }
+        if !reflect.DeepEqual(query.Args, test.values) {
+          t.Fatalf("values = %v, want %v", query.Args, test.values)
+        }
+      }
+    })
+  }
+}
+
+func TestSchemaTenantTable(t *testing.T) {
+  ctx := mysql.WithTenant(context.Background(), "acme")
+  tests := []struct {
+    name string
+    run  func(*mysql.DB)
+  }{
+    {"upsert", func(d *mysql.DB) {
+      d.Upsert("orders", map[string]interface{}{"id": 1, "total": 2}, "id")
+    }},
+    {"select in batches", func(d *mysql.DB) {
+      d.SelectInBatches("orders", nil, 10, func([]map[string]interface{}) error { return nil })
+    }},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      mock := mysqltest.New(t)
+      mysql.ResetSchemaCache()
+      mock.On("`COLUMNS`").Rows(
+        []string{"COLUMN_NAME", "ORDINAL_POSITION", "DATA_TYPE", "COLUMN_TYPE", "IS_NULLABLE",
+          "COLUMN_DEFAULT", "COLUMN_KEY", "EXTRA", "LENGTH", "PRECISION", "SCALE"},
+        []interface{}{"id", 1, "int", "int", "NO", nil, "PRI", "", 0, 10, 0},
+      )
+      mock.On("`STATISTICS`").Rows(
+        []string{"INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME"}, []interface{}{"PRIMARY", 0, "id"},
+      )
+      test.run(mysql.WithContext(ctx))
+
+      queries := mock.Queries()
+      if !strings.Contains(queries[0].SQL, "INFORMATION_SCHEMA") {
+        t.Fatalf("first query = %q, want a schema query", queries[0].SQL)
+      }
+      if want := []interface{}{"acme_orders"}; !reflect.DeepEqual(queries[0].Args, want) {
+        t.Fatalf("schema values = %v, want %v", queries[0].Args, want)
+      }
+      if last := queries[len(queries)-1].SQL; !strings.Contains(last, "`acme_orders`") {
+        t.Fatalf("last query = %q, want the tenant table", last)
+      }
+    })
+  }
+}
+
+func TestSchemaOfHandle(t *testing.T) {
+  std := mysqltest.New(t)
+  mysql.ResetSchemaCache()
+  handle, mock := mysqltest.NewDB()
+  mock.On("`STATISTICS`").Rows(
+    []string{"INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME"}, []interface{}{"PRIMARY", 0, "id"},
+  )
+
+  mysql.Columns("orders")
+  handle.Upsert("orders", map[string]interface{}{"id": 1, "total": 2}, "id")
+
+  // Cached definitions of the default connection aren't used by the handle
+  if got := len(std.Queries()); got != 3 { t.Fatalf("default connection queries = %d, want 3", got) }
+  sql := mock.SQL()
+  if len(sql) != 4 || !strings.Contains(sql[0], "INFORMATION_SCHEMA") || !strings.HasPrefix(sql[3], "INSERT") {
+    t.Fatalf("handle queries = %q, want the schema queries and the insert", sql)
+  }
+}
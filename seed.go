@@ -0,0 +1,165 @@
+package mysql
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+var fake_words = strings.Fields(`lorem ipsum dolor sit amet consectetur 
+  adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna 
+  aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris nisi`)
+
+var fake_names = []string{
+  "Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Grace", "Heidi", "Ivan",
+  "Judy", "Mallory", "Niaj", "Olivia", "Peggy", "Rupert", "Sybil", "Trent",
+}
+
+// Development helper which inspects the schema of a table and generates `n` 
+// rows of plausible fake data. Values respect column types, lengths, `ENUM` 
+// and `SET` definitions, and foreign keys reference random existing rows. 
+// `AUTO_INCREMENT` and generated columns are left to the server.
+//
+// Parameters:
+//   - `table`: name of the table
+//   - `n`: number of rows to generate
+// Returns:
+//   - []map[string]interface{}: generated rows, ready for `Insert(...)`
+func FakeRows(table string, n int) []map[string]interface{} {
+  random  := rand.New(rand.NewSource(time.Now().UnixNano()))
+  columns := Columns(table)
+  if len(columns) == 0 {
+    panic(fmt.Errorf("mysql: table %q not found", table))
+  }
+
+  references := map[string][]interface{}{}
+  for _, fk := range ForeignKeys(table) {
+    options := map[string]interface{}{
+      "column": fk.ReferencedColumn,
+      "limit":  1000,
+    }
+    var values []interface{}
+    for _, ref := range Select(fk.ReferencedTable, nil, options) {
+      values = append(values, ref[fk.ReferencedColumn])
+    }
+    references[fk.Column] = values
+  }
+
+  rows := make([]map[string]interface{}, n)
+  for i := range rows {
+    row := map[string]interface{}{}
+    for _, column := range columns {
+      if column.IsGenerated() { continue }
+
+      if values, ok := references[column.Name]; ok {
+        if len(values) > 0 {
+          row[column.Name] = values[random.Intn(len(values))]
+        } else if column.Nullable {
+          row[column.Name] = nil
+        } else {
+          panic(fmt.Errorf("mysql: no rows to reference from %s.%s", table, column.Name))
+        }
+        continue
+      }
+
+      if column.Nullable && random.Intn(10) == 0 {
+        row[column.Name] = nil
+        continue
+      }
+      row[column.Name] = fake_value(random, &column)
+    }
+    rows[i] = row
+  }
+  return rows
+}
+
+// Same api with `FakeRows(...)` except generated rows are inserted into the 
+// table. Useful for seeding local environments without writing fixtures.
+//
+// Example:
+//   mysql.Seed("users", 100)
+//   mysql.Seed("orders", 1000) // references random existing users
+func Seed(table string, n int) []map[string]interface{} {
+  rows := FakeRows(table, n)
+  for _, row := range rows {
+    Insert(table, row)
+  }
+  return rows
+}
+
+func fake_value(random *rand.Rand, c *Column) interface{} {
+  if values := c.EnumValues(); len(values) > 0 {
+    return values[random.Intn(len(values))]
+  }
+
+  name := strings.ToLower(c.Name)
+  switch c.DataType {
+  case "tinyint":
+    if strings.HasPrefix(strings.ToLower(c.ColumnType), "tinyint(1)") {
+      return random.Intn(2)
+    }
+    return fake_int(random, c, 127)
+  case "smallint":  return fake_int(random, c, 32767)
+  case "mediumint": return fake_int(random, c, 8388607)
+  case "int", "integer", "bigint": return fake_int(random, c, 1000000)
+  case "bit": return random.Intn(2)
+  case "decimal", "numeric":
+    max := 1.0
+    for i := int64(0); i < c.Precision-c.Scale && i < 6; i++ { max *= 10 }
+    value := random.Float64() * max
+    if !c.IsUnsigned() && random.Intn(4) == 0 { value = -value }
+    return fmt.Sprintf("%.*f", c.Scale, value)
+  case "float", "double", "real":
+    return random.Float64() * 1000
+  case "date":
+    return fake_time(random).Format("2006-01-02")
+  case "datetime", "timestamp":
+    return fake_time(random).Format("2006-01-02 15:04:05")
+  case "time":
+    return fmt.Sprintf("%02d:%02d:%02d", random.Intn(24), random.Intn(60), random.Intn(60))
+  case "year":
+    return 2000 + random.Intn(30)
+  case "json":
+    return fmt.Sprintf(`{"%s": %d}`, fake_words[random.Intn(len(fake_words))], random.Intn(100))
+  case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+    size := 16
+    if c.MaxLength > 0 && c.MaxLength < int64(size) { size = int(c.MaxLength) }
+    bytes := make([]byte, size)
+    random.Read(bytes)
+    return bytes
+  }
+
+  var value string
+  switch {
+  case strings.Contains(name, "email"):
+    value = fmt.Sprintf("user%d@example.com", random.Intn(1000000000))
+  case strings.Contains(name, "name"):
+    value = fake_names[random.Intn(len(fake_names))]
+  case strings.Contains(name, "phone"):
+    value = fmt.Sprintf("+1555%07d", random.Intn(10000000))
+  case strings.Contains(name, "url"):
+    value = fmt.Sprintf("https://example.com/%d", random.Intn(1000000))
+  default:
+    words := make([]string, 3+random.Intn(8))
+    for i := range words {
+      words[i] = fake_words[random.Intn(len(fake_words))]
+    }
+    value = strings.Join(words, " ")
+  }
+  if c.MaxLength > 0 && int64(len(value)) > c.MaxLength {
+    value = value[:c.MaxLength]
+  }
+  return value
+}
+
+func fake_int(random *rand.Rand, c *Column, max int) int {
+  value := random.Intn(max)
+  if !c.IsUnsigned() && random.Intn(4) == 0 { value = -value }
+  return value
+}
+
+func fake_time(random *rand.Rand) time.Time {
+  seconds := random.Int63n(int64(365 * 24 * time.Hour / time.Second))
+  return time.Now().Add(-time.Duration(seconds) * time.Second)
+}
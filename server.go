@@ -0,0 +1,140 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Server settings which commonly cause trouble when they don't match the 
+// application's expectations.
+type Server struct {
+  Version          string
+  CharacterSet     string
+  Collation        string
+  SQLMode          string
+  TimeZone         string
+  MaxAllowedPacket int64
+}
+
+// Reads the current server and connection settings.
+//
+// Returns:
+//   - *Server: version, character set, collation, sql_mode, time zone and 
+//              max_allowed_packet of the connected server
+//
+// Example:
+//   info := mysql.ServerInfo()
+//   if !strings.HasPrefix(info.CharacterSet, "utf8mb4") {
+//     log.Println("warning: server charset is", info.CharacterSet)
+//   }
+func ServerInfo() *Server {
+  query := `SELECT VERSION(), @@character_set_connection, 
+    @@collation_connection, @@sql_mode, @@time_zone, @@system_time_zone, 
+    @@max_allowed_packet;`
+  var time_zone, system_time_zone string
+  info := &Server{}
+  err  := std.scan_row([]interface{}{
+    &info.Version,
+    &info.CharacterSet,
+    &info.Collation,
+    &info.SQLMode,
+    &time_zone,
+    &system_time_zone,
+    &info.MaxAllowedPacket,
+  }, query)
+  if err != nil { handle_error(err, query, nil) }
+
+  info.TimeZone = time_zone
+  if time_zone == "SYSTEM" {
+    info.TimeZone = "SYSTEM (" + system_time_zone + ")"
+  }
+  return info
+}
+
+func log_server_info() {
+  info := ServerInfo()
+  Logger.Printf("MySQL server %s", info.Version)
+  Logger.Printf("  character set:      %s (%s)", info.CharacterSet, info.Collation)
+  Logger.Printf("  sql_mode:           %s", info.SQLMode)
+  Logger.Printf("  time zone:          %s", info.TimeZone)
+  Logger.Printf("  max_allowed_packet: %d", info.MaxAllowedPacket)
+}
+
+// Connection of the server process list, see `ProcessList()`.
+type Process struct {
+  ID      int64
+  User    string
+  Host    string
+  // Default database of the connection, "" when none is selected
+  DB      string
+  // Command type like "Query" or "Sleep"
+  Command string
+  // Time spent in the current state
+  Time    time.Duration
+  State   string
+  // Running statement, "" when idle
+  Info    string
+}
+
+// Returns the connections of the server, every connection with the PROCESS 
+// privilege, otherwise the connections of the user.
+//
+// Example:
+//   processes, err := mysql.ProcessList()
+//   for _, p := range processes {
+//     if p.Command == "Query" && p.Time > time.Minute { mysql.KillQuery(p.ID) }
+//   }
+func (d *DB) ProcessList() (processes []Process, err error) {
+  defer RecoverError(&err)
+  rows := d.ExecQuery(`SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO 
+    FROM information_schema.PROCESSLIST ORDER BY ID;`)
+  defer rows.Close()
+
+  for rows.Next() {
+    var p Process
+    var db, state, info sql.NullString
+    var seconds int64
+    err := rows.Scan(&p.ID, &p.User, &p.Host, &db, &p.Command, &seconds, &state, &info)
+    if err != nil { return nil, err }
+    p.DB, p.State, p.Info = db.String, state.String, info.String
+    p.Time = time.Duration(seconds) * time.Second
+    processes = append(processes, p)
+  }
+  return processes, rows.Err()
+}
+
+// Returns the global status counters of `SHOW GLOBAL STATUS` by name, e.g. 
+// "Threads_connected" or "Slow_queries".
+func (d *DB) ServerStatus() (map[string]string, error) {
+  return d.show_values("SHOW GLOBAL STATUS;")
+}
+
+// Returns the global system variables of `SHOW GLOBAL VARIABLES` by name, 
+// e.g. "max_connections" or "innodb_buffer_pool_size".
+func (d *DB) ServerVariables() (map[string]string, error) {
+  return d.show_values("SHOW GLOBAL VARIABLES;")
+}
+
+// Stops the statement running on connection `id` of the process list, the 
+// connection itself stays open.
+func (d *DB) KillQuery(id int64) (err error) {
+  defer RecoverError(&err)
+  d.Exec(fmt.Sprintf("KILL QUERY %d;", id))
+  return nil
+}
+
+func (d *DB) show_values(query string) (values map[string]string, err error) {
+  defer RecoverError(&err)
+  rows := d.ExecQuery(query)
+  defer rows.Close()
+
+  values = map[string]string{}
+  for rows.Next() {
+    var name string
+    var value sql.NullString
+    if err := rows.Scan(&name, &value); err != nil { return nil, err }
+    values[name] = value.String
+  }
+  return values, rows.Err()
+}
@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Connector setting the session variables of `Config.Session` on every new 
+// connection, so they survive connections being replaced by the pool.
+type session_connector struct {
+  driver.Connector
+  query  string
+  values []driver.NamedValue
+}
+
+// Wraps `connector` to set the session variables of `cfg`, or returns it 
+// unchanged without session variables.
+func with_session(connector driver.Connector, cfg *Config) driver.Connector {
+  if len(cfg.Session) == 0 { return connector }
+
+  names := make([]string, 0, len(cfg.Session))
+  for name := range cfg.Session {
+    if !is_identifier(name) { panic(fmt.Errorf("mysql: invalid session variable %q", name)) }
+    names = append(names, name)
+  }
+  sort.Strings(names)
+
+  session := session_connector{Connector: connector}
+  sets    := make([]string, len(names))
+  for i, name := range names {
+    value := cfg.Session[name]
+    // Numbers from environment variables or DSN parameters are strings, 
+    // integer variables like wait_timeout don't accept them.
+    if text, ok := value.(string); ok {
+      if number, err := strconv.ParseInt(text, 10, 64); err == nil { value = number }
+    }
+    converted, err := driver.DefaultParameterConverter.ConvertValue(value)
+    if err != nil { panic(fmt.Errorf("mysql: session variable %s: %w", name, err)) }
+
+    sets[i] = "@@SESSION." + name + " = ?"
+    session.values = append(session.values, driver.NamedValue{Ordinal: i + 1, Value: converted})
+  }
+  session.query = "SET " + strings.Join(sets, ", ")
+  return session
+}
+
+func (c session_connector) Connect(ctx context.Context) (driver.Conn, error) {
+  conn, err := c.Connector.Connect(ctx)
+  if err != nil { return nil, err }
+  if err := c.set(ctx, conn); err != nil {
+    conn.Close()
+    return nil, err
+  }
+  return conn, nil
+}
+
+func (c session_connector) set(ctx context.Context, conn driver.Conn) error {
+  var stmt driver.Stmt
+  var err error
+  if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+    stmt, err = preparer.PrepareContext(ctx, c.query)
+  } else {
+    stmt, err = conn.Prepare(c.query)
+  }
+  if err != nil { return err }
+  defer stmt.Close()
+
+  if execer, ok := stmt.(driver.StmtExecContext); ok {
+    _, err = execer.ExecContext(ctx, c.values)
+    return err
+  }
+  values := make([]driver.Value, len(c.values))
+  for i, value := range c.values { values[i] = value.Value }
+  _, err = stmt.Exec(values)
+  return err
+}
@@ -0,0 +1,93 @@
+package mysql
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// Result of running a candidate query next to the current one, see 
+// `Shadow(...)`.
+type ShadowReport struct {
+  Name              string
+  Rows              int
+  CandidateRows     int
+  Duration          time.Duration
+  CandidateDuration time.Duration
+  // Reports whether both queries returned exactly the same rows in the same 
+  // order.
+  Equal             bool
+  // Panic value of the candidate query converted to an error, if any.
+  CandidateError    error
+}
+
+// Reports whether the candidate query failed or returned different rows.
+func (r *ShadowReport) Differs() bool {
+  return r.CandidateError != nil || !r.Equal
+}
+
+// Receives every shadow report. The default reporter logs reports which 
+// differ. Replace it to collect metrics instead.
+var ShadowReporter = func(report *ShadowReport) {
+  if !report.Differs() { return }
+  Logger.Printf(
+    "shadow query %q differs: rows %d vs %d, duration %s vs %s, error: %v",
+    report.Name,
+    report.Rows, report.CandidateRows,
+    report.Duration, report.CandidateDuration,
+    report.CandidateError,
+  )
+}
+
+// Runs `current` and returns its rows. For a sampled fraction `rate` (0 to 1) 
+// of calls `candidate` runs in parallel, and both results are compared and 
+// sent to `ShadowReporter`. Use it to validate a rewritten query on real 
+// traffic before switching to it. The candidate never affects the caller, its 
+// panics are recovered and reported.
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   rows := mysql.Shadow("active users", 0.05, func() []map[string]interface{} {
+//     return mysql.Select("users", _json{"active": 1})
+//   }, func() []map[string]interface{} {
+//     return mysql.Select("users", _json{"deactivated_at": nil})
+//   })
+func Shadow(
+  name string,
+  rate float64,
+  current, candidate func() []map[string]interface{},
+) []map[string]interface{} {
+  if rate <= 0 || rand.Float64() >= rate { return current() }
+
+  report := &ShadowReport{Name: name}
+  candidate_rows := make(chan []map[string]interface{}, 1)
+  go func() {
+    var rows []map[string]interface{}
+    defer func() {
+      if err := recover(); err != nil {
+        report.CandidateError = fmt.Errorf("%v", err)
+      }
+      candidate_rows <- rows
+    }()
+
+    start := time.Now()
+    rows   = candidate()
+    report.CandidateDuration = time.Since(start)
+    report.CandidateRows     = len(rows)
+  }()
+
+  start := time.Now()
+  rows  := current()
+  duration := time.Since(start)
+
+  go func() {
+    shadow := <-candidate_rows
+    report.Rows     = len(rows)
+    report.Duration = duration
+    report.Equal    = report.CandidateError == nil && reflect.DeepEqual(rows, shadow)
+    ShadowReporter(report)
+  }()
+  return rows
+}
@@ -0,0 +1,216 @@
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	m "github.com/go-sql-driver/mysql"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSH bastion settings of `Config`, to connect to a server in a private 
+// network from developer machines and jobs. Connections to `Host` and `Port`, 
+// or to `Socket`, are opened by the bastion, so they're resolved there.
+//
+// Example config.yml:
+//   database:
+//     host: db.internal
+//     ssh:
+//       host: bastion.example.com
+//       user: deploy
+//       key_file: ~/.ssh/id_ed25519
+type SSHConfig struct {
+  // Address of the bastion, the port is 22 when omitted
+  Host string `yaml:"host"`
+  User string `yaml:"user"`
+  // Path of the private key, or the PEM encoded key itself, with its 
+  // passphrase when it's encrypted
+  KeyFile    string `yaml:"key_file,omitempty"`
+  Key        string `yaml:"key,omitempty"`
+  Passphrase string `yaml:"passphrase,omitempty"`
+  Password   string `yaml:"password,omitempty"`
+  // Authenticate with the keys of the ssh-agent of `SSH_AUTH_SOCK`
+  Agent bool `yaml:"agent,omitempty"`
+  // Path of the known_hosts file verifying the bastion,
+  // "~/.ssh/known_hosts" when empty
+  KnownHosts string `yaml:"known_hosts,omitempty"`
+  // Skip verification of the host key of the bastion. Only for development, 
+  // the connection is encrypted but not protected against impersonation.
+  InsecureIgnoreHostKey bool `yaml:"insecure_ignore_host_key,omitempty"`
+}
+
+// SSH connection to a bastion, shared by the connection pools using it.
+type ssh_tunnel struct {
+  config *SSHConfig
+  mu     sync.Mutex
+  client *ssh.Client
+  // Connection to the ssh-agent of the client, closed with it
+  agent  net.Conn
+}
+
+var ssh_tunnels struct {
+  sync.Mutex
+  tunnels map[string]*ssh_tunnel
+}
+
+// Registers a dialer of the driver opening connections of `network` through 
+// the bastion, and returns its network name.
+func ssh_network(config *SSHConfig, network string) string {
+  // Pools with the same settings share the bastion connection
+  sum  := sha256.Sum256([]byte(fmt.Sprintf("%#v", *config)))
+  key  := hex.EncodeToString(sum[:8])
+  name := "ssh-" + key + "-" + network
+
+  ssh_tunnels.Lock()
+  defer ssh_tunnels.Unlock()
+  if ssh_tunnels.tunnels == nil { ssh_tunnels.tunnels = map[string]*ssh_tunnel{} }
+  tunnel, ok := ssh_tunnels.tunnels[key]
+  if !ok {
+    copied := *config
+    tunnel  = &ssh_tunnel{config: &copied}
+    ssh_tunnels.tunnels[key] = tunnel
+  }
+  m.RegisterDialContext(name, func(ctx context.Context, address string) (net.Conn, error) {
+    return tunnel.dial(ctx, network, address)
+  })
+  return name
+}
+
+// Opens a connection through the bastion, connecting to it again once when 
+// its connection is broken.
+func (t *ssh_tunnel) dial(ctx context.Context, network, address string) (net.Conn, error) {
+  client, err := t.connect(ctx)
+  if err != nil { return nil, err }
+  conn, err := client.DialContext(ctx, network, address)
+  if err == nil || ctx.Err() != nil { return conn, err }
+
+  t.reset(client)
+  if client, err = t.connect(ctx); err != nil { return nil, err }
+  return client.DialContext(ctx, network, address)
+}
+
+func (t *ssh_tunnel) connect(ctx context.Context) (*ssh.Client, error) {
+  t.mu.Lock()
+  defer t.mu.Unlock()
+  if t.client != nil { return t.client, nil }
+
+  config, agent_conn, err := t.config.client_config()
+  if err != nil { return nil, err }
+  close_agent := func() {
+    if agent_conn != nil { agent_conn.Close() }
+  }
+  address := t.config.Host
+  if _, _, err := net.SplitHostPort(address); err != nil {
+    address = net.JoinHostPort(address, "22")
+  }
+
+  var dialer net.Dialer
+  conn, err := dialer.DialContext(ctx, "tcp", address)
+  if err != nil {
+    close_agent()
+    return nil, fmt.Errorf("mysql: ssh %s: %w", address, err)
+  }
+  if deadline, ok := ctx.Deadline(); ok { conn.SetDeadline(deadline) }
+  ssh_conn, channels, requests, err := ssh.NewClientConn(conn, address, config)
+  if err != nil {
+    conn.Close()
+    close_agent()
+    return nil, fmt.Errorf("mysql: ssh %s: %w", address, err)
+  }
+  conn.SetDeadline(time.Time{})
+
+  t.client = ssh.NewClient(ssh_conn, channels, requests)
+  t.agent  = agent_conn
+  return t.client, nil
+}
+
+// Closes a broken bastion connection, unless it was replaced already.
+func (t *ssh_tunnel) reset(client *ssh.Client) {
+  t.mu.Lock()
+  defer t.mu.Unlock()
+  if t.client == client {
+    t.client.Close()
+    t.client = nil
+    if t.agent != nil { t.agent.Close() }
+    t.agent = nil
+  }
+}
+
+// Returns the client settings, and the connection to the ssh-agent or nil, 
+// which the caller closes.
+func (s *SSHConfig) client_config() (_ *ssh.ClientConfig, agent_conn net.Conn, err error) {
+  defer func() {
+    if err != nil && agent_conn != nil {
+      agent_conn.Close()
+      agent_conn = nil
+    }
+  }()
+
+  var methods []ssh.AuthMethod
+  if s.Key != "" || s.KeyFile != "" {
+    key := []byte(s.Key)
+    if s.KeyFile != "" {
+      var err error
+      if key, err = os.ReadFile(expand_home(s.KeyFile)); err != nil { return nil, nil, err }
+    }
+    var signer ssh.Signer
+    var err error
+    if s.Passphrase != "" {
+      signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(s.Passphrase))
+    } else {
+      signer, err = ssh.ParsePrivateKey(key)
+    }
+    if err != nil { return nil, nil, fmt.Errorf("mysql: ssh key: %w", err) }
+    methods = append(methods, ssh.PublicKeys(signer))
+  }
+  if s.Agent {
+    socket := os.Getenv("SSH_AUTH_SOCK")
+    if socket == "" { return nil, nil, errors.New("mysql: ssh agent: SSH_AUTH_SOCK is not set") }
+    if agent_conn, err = net.Dial("unix", socket); err != nil {
+      return nil, nil, fmt.Errorf("mysql: ssh agent: %w", err)
+    }
+    methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agent_conn).Signers))
+  }
+  if s.Password != "" { methods = append(methods, ssh.Password(s.Password)) }
+
+  host_key := ssh.InsecureIgnoreHostKey()
+  if !s.InsecureIgnoreHostKey {
+    path := s.KnownHosts
+    if path == "" { path = "~/.ssh/known_hosts" }
+    callback, err := knownhosts.New(expand_home(path))
+    if err != nil { return nil, agent_conn, fmt.Errorf("mysql: ssh known hosts: %w", err) }
+    host_key = callback
+  }
+
+  config := &ssh.ClientConfig{User: s.User, Auth: methods, HostKeyCallback: host_key}
+  return config, agent_conn, nil
+}
+
+// Returns the problems of the settings for `Config.Validate()`.
+func (s *SSHConfig) problems() []string {
+  var problems []string
+  if s.Host == "" { problems = append(problems, "ssh host is required") }
+  if s.User == "" { problems = append(problems, "ssh user is required") }
+  if s.Key == "" && s.KeyFile == "" && s.Password == "" && !s.Agent {
+    problems = append(problems, "ssh needs a key, a password or the agent")
+  }
+  return problems
+}
+
+func expand_home(path string) string {
+  if path != "~" && !strings.HasPrefix(path, "~/") { return path }
+  home, err := os.UserHomeDir()
+  if err != nil { return path }
+  return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSSHAgentConnection(t *testing.T) {
+  socket := filepath.Join(t.TempDir(), "agent.sock")
+  listener, err := net.Listen("unix", socket)
+  if err != nil { t.Fatal(err) }
+  defer listener.Close()
+  t.Setenv("SSH_AUTH_SOCK", socket)
+
+  tests := []struct {
+    name   string
+    config SSHConfig
+    closed bool
+  }{
+    {"returned to the tunnel", SSHConfig{Agent: true, InsecureIgnoreHostKey: true}, false},
+    {"closed on errors", SSHConfig{Agent: true, KnownHosts: filepath.Join(t.TempDir(), "missing")}, true},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      _, agent_conn, err := test.config.client_config()
+      if (err != nil) != test.closed { t.Fatalf("err = %v", err) }
+      if (agent_conn == nil) != test.closed { t.Fatalf("agent connection = %v", agent_conn) }
+      if agent_conn != nil { defer agent_conn.Close() }
+
+      // The agent side reads EOF once the client closed its connection
+      accepted, err := listener.Accept()
+      if err != nil { t.Fatal(err) }
+      defer accepted.Close()
+      accepted.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+      _, err = accepted.Read(make([]byte, 1))
+      if closed := err != nil && !is_timeout(err); closed != test.closed {
+        t.Fatalf("agent connection closed = %v, want %v", closed, test.closed)
+      }
+    })
+  }
+}
+
+func is_timeout(err error) bool {
+  net_err, ok := err.(net.Error)
+  return ok && net_err.Timeout()
+}
@@ -0,0 +1,462 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Package-level functions run on the default handle connected by `Init(...)`, 
+// see the `DB` methods of the same names for documentation.
+
+// Returns a handle of the default connection whose queries use `ctx`, see 
+// `DB.WithContext`.
+func WithContext(ctx context.Context) *DB { return std.WithContext(ctx) }
+
+// Returns statistics of the default connection pool, see `DB.Stats`.
+func Stats() sql.DBStats { return std.Stats() }
+
+// Starts a transaction on the default connection, see `DB.Begin`.
+func Begin(options ...map[string]interface{}) *Tx { return std.Begin(options...) }
+
+// Starts a transaction on the default connection carried by the returned 
+// context, see `DB.BeginTxContext`.
+func BeginTxContext(
+  ctx context.Context,
+  options ...map[string]interface{},
+) (context.Context, *Tx) {
+  return std.BeginTxContext(ctx, options...)
+}
+
+// Runs `fn` inside a transaction of the default connection, see 
+// `DB.Transaction`.
+func Transaction(
+  ctx context.Context,
+  fn func(ctx context.Context) error,
+  options ...map[string]interface{},
+) error {
+  return std.Transaction(ctx, fn, options...)
+}
+
+// Starts a unit of work on the default connection carried by the returned 
+// context, see `DB.BeginUnitOfWork`.
+func BeginUnitOfWork(ctx context.Context) (context.Context, *UnitOfWork) {
+  return std.BeginUnitOfWork(ctx)
+}
+
+// See `DB.Select`.
+func Select(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) []map[string]interface{} {
+  return std.Select(table, where, options...)
+}
+
+// See `DB.SelectEach`.
+func SelectEach(
+  table string,
+  where, options map[string]interface{},
+  fn func(row map[string]interface{}) error,
+) error {
+  return std.SelectEach(table, where, options, fn)
+}
+
+// See `DB.SelectInBatches`.
+func SelectInBatches(
+  table string,
+  where map[string]interface{},
+  batch_size int,
+  fn func(rows []map[string]interface{}) error,
+) error {
+  return std.SelectInBatches(table, where, batch_size, fn)
+}
+
+// See `DB.First`.
+func First(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) map[string]interface{} {
+  return std.First(table, where, options...)
+}
+
+// See `DB.Find`.
+func Find(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (map[string]interface{}, error) {
+  return std.Find(table, where, options...)
+}
+
+// See `DB.Pluck`.
+func Pluck(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) ([]interface{}, error) {
+  return std.Pluck(table, column, where, options...)
+}
+
+// See `DB.Value`.
+func Value(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (interface{}, error) {
+  return std.Value(table, column, where, options...)
+}
+
+// See `DB.Int64Value`.
+func Int64Value(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (int64, error) {
+  return std.Int64Value(table, column, where, options...)
+}
+
+// See `DB.StringValue`.
+func StringValue(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (string, error) {
+  return std.StringValue(table, column, where, options...)
+}
+
+// See `DB.TimeValue`.
+func TimeValue(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (time.Time, error) {
+  return std.TimeValue(table, column, where, options...)
+}
+
+// See `DB.DeleteInBatches`.
+func DeleteInBatches(
+  table string,
+  where map[string]interface{},
+  batch_size int,
+  options ...map[string]interface{},
+) (int64, error) {
+  return std.DeleteInBatches(table, where, batch_size, options...)
+}
+
+// See `DB.AcquireLock`.
+func AcquireLock(name string, timeout time.Duration) (func(), error) {
+  return std.AcquireLock(name, timeout)
+}
+
+// See `DB.ProcessList`.
+func ProcessList() ([]Process, error) { return std.ProcessList() }
+
+// See `DB.ServerStatus`.
+func ServerStatus() (map[string]string, error) { return std.ServerStatus() }
+
+// See `DB.ServerVariables`.
+func ServerVariables() (map[string]string, error) { return std.ServerVariables() }
+
+// See `DB.KillQuery`.
+func KillQuery(id int64) error { return std.KillQuery(id) }
+
+// See `DB.SelectAsync`.
+func SelectAsync(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) *Future[[]map[string]interface{}] {
+  return std.SelectAsync(table, where, options...)
+}
+
+// See `DB.ExecAsync`.
+func ExecAsync(query string, values ...interface{}) *Future[sql.Result] {
+  return std.ExecAsync(query, values...)
+}
+
+// See `DB.Paginate`.
+func Paginate(
+  table string,
+  where map[string]interface{},
+  page, per_page int,
+  options ...map[string]interface{},
+) *Pagination {
+  return std.Paginate(table, where, page, per_page, options...)
+}
+
+// See `DB.Count`.
+func Count(table string, where map[string]interface{}) (int64, error) {
+  return std.Count(table, where)
+}
+
+// See `DB.Sum`.
+func Sum(table, column string, where map[string]interface{}) (float64, error) {
+  return std.Sum(table, column, where)
+}
+
+// See `DB.SumInt64`.
+func SumInt64(table, column string, where map[string]interface{}) (int64, error) {
+  return std.SumInt64(table, column, where)
+}
+
+// See `DB.Min`.
+func Min(table, column string, where map[string]interface{}) (float64, error) {
+  return std.Min(table, column, where)
+}
+
+// See `DB.Max`.
+func Max(table, column string, where map[string]interface{}) (float64, error) {
+  return std.Max(table, column, where)
+}
+
+// See `DB.MinTime`.
+func MinTime(table, column string, where map[string]interface{}) (time.Time, error) {
+  return std.MinTime(table, column, where)
+}
+
+// See `DB.MaxTime`.
+func MaxTime(table, column string, where map[string]interface{}) (time.Time, error) {
+  return std.MaxTime(table, column, where)
+}
+
+// See `DB.MinString`.
+func MinString(table, column string, where map[string]interface{}) (string, error) {
+  return std.MinString(table, column, where)
+}
+
+// See `DB.MaxString`.
+func MaxString(table, column string, where map[string]interface{}) (string, error) {
+  return std.MaxString(table, column, where)
+}
+
+// See `DB.Avg`.
+func Avg(table, column string, where map[string]interface{}) (float64, error) {
+  return std.Avg(table, column, where)
+}
+
+// See `DB.Insert`.
+func Insert(table string, data map[string]interface{}) sql.Result {
+  return std.Insert(table, data)
+}
+
+// See `DB.InsertIgnore`.
+func InsertIgnore(table string, data map[string]interface{}) sql.Result {
+  return std.InsertIgnore(table, data)
+}
+
+// See `DB.UpdateCount`.
+func UpdateCount(
+  table string,
+  data, where map[string]interface{},
+  options ...map[string]interface{},
+) (int64, error) {
+  return std.UpdateCount(table, data, where, options...)
+}
+
+// See `DB.DeleteCount`.
+func DeleteCount(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (int64, error) {
+  return std.DeleteCount(table, where, options...)
+}
+
+// See `DB.UpdateMany`.
+func UpdateMany(
+  table string,
+  rows []map[string]interface{},
+  key_column string,
+) sql.Result {
+  return std.UpdateMany(table, rows, key_column)
+}
+
+// See `DB.Increment`.
+func Increment(
+  table, column string,
+  amount interface{},
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.Increment(table, column, amount, where, options...)
+}
+
+// See `DB.Decrement`.
+func Decrement(
+  table, column string,
+  amount interface{},
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.Decrement(table, column, amount, where, options...)
+}
+
+// See `DB.InsertFromSelect`.
+func InsertFromSelect(
+  dest_table string,
+  columns []string,
+  src_table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.InsertFromSelect(dest_table, columns, src_table, where, options...)
+}
+
+// See `DB.Replace`.
+func Replace(table string, data map[string]interface{}) sql.Result {
+  return std.Replace(table, data)
+}
+
+// See `DB.InsertGetID`.
+func InsertGetID(table string, data map[string]interface{}) (int64, error) {
+  return std.InsertGetID(table, data)
+}
+
+// See `DB.InsertManyResilient`.
+func InsertManyResilient(
+  table string,
+  rows []map[string]interface{},
+  options ...map[string]interface{},
+) (*InsertReport, error) {
+  return std.InsertManyResilient(table, rows, options...)
+}
+
+// See `DB.InsertRow`.
+func InsertRow(table string, data map[string]interface{}) sql.Result {
+  return std.InsertRow(table, data)
+}
+
+// See `DB.Upsert`.
+func Upsert(
+  table string,
+  data map[string]interface{},
+  conflict ...string,
+) sql.Result {
+  return std.Upsert(table, data, conflict...)
+}
+
+// See `DB.Update`.
+func Update(
+  table string,
+  data, where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.Update(table, data, where, options...)
+}
+
+// See `DB.UpdateFirst`.
+func UpdateFirst(
+  table string,
+  data, where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.UpdateFirst(table, data, where, options...)
+}
+
+// See `DB.Delete`.
+func Delete(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.Delete(table, where, options...)
+}
+
+// See `DB.DeleteFirst`.
+func DeleteFirst(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) sql.Result {
+  return std.DeleteFirst(table, where, options...)
+}
+
+// See `DB.DeleteCascade`.
+func DeleteCascade(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (int64, error) {
+  return std.DeleteCascade(table, where, options...)
+}
+
+// See `DB.Call`.
+func Call(procedure string, args ...interface{}) *CallResult {
+  return std.Call(procedure, args...)
+}
+
+// See `DB.QueryMulti`.
+func QueryMulti(query string, values ...interface{}) *ResultSets {
+  return std.QueryMulti(query, values...)
+}
+
+// See `DB.Explain`.
+func Explain(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) []map[string]interface{} {
+  return std.Explain(table, where, options...)
+}
+
+// See `DB.ExplainQuery`.
+func ExplainQuery(query string, values ...interface{}) []map[string]interface{} {
+  return std.ExplainQuery(query, values...)
+}
+
+// See `DB.Table`.
+func Table(table string) *Query {
+  return std.Table(table)
+}
+
+// See `DB.ExecQuery`.
+func ExecQuery(query string, values ...interface{}) *sql.Rows {
+  return std.ExecQuery(query, values...)
+}
+
+// See `DB.ExecQueryRow`.
+func ExecQueryRow(query string, values ...interface{}) *sql.Row {
+  return std.ExecQueryRow(query, values...)
+}
+
+// See `DB.ScanOne`.
+func ScanOne(query string, values []interface{}, dest ...interface{}) error {
+  return std.ScanOne(query, values, dest...)
+}
+
+// See `DB.Exec`.
+func Exec(query string, values ...interface{}) sql.Result {
+  return std.Exec(query, values...)
+}
+
+// See `DB.ExecNamed`.
+func ExecNamed(query string, params map[string]interface{}) sql.Result {
+  return std.ExecNamed(query, params)
+}
+
+// See `DB.QueryNamed`.
+func QueryNamed(query string, params map[string]interface{}) *sql.Rows {
+  return std.QueryNamed(query, params)
+}
+
+// See `DB.Columns`.
+func Columns(table string) []Column {
+  return std.Columns(table)
+}
+
+// See `DB.ForeignKeys`.
+func ForeignKeys(table string) []ForeignKey {
+  return std.ForeignKeys(table)
+}
+
+// See `DB.Indexes`.
+func Indexes(table string) []Index {
+  return std.Indexes(table)
+}
+
+// See `DB.WarmSchemaCache`.
+func WarmSchemaCache(tables ...string) error {
+  return std.WarmSchemaCache(tables...)
+}
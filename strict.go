@@ -0,0 +1,29 @@
+package mysql
+
+// Maximum number of rows read by `Select(...)` given without the "limit" 
+// option, protecting services from accidentally reading entire tables into 
+// memory. 0 disables the injected LIMIT. A query can opt out with the option 
+// `"unbounded": true`. `SelectEach(...)` streams rows and is never limited, 
+// neither are queries of `BuildSelect(...)` or `Explain(...)` which don't 
+// read rows.
+var MaxSelectLimit = 0
+
+// Called when `MaxSelectLimit` was injected into a query. The default handler 
+// logs the query, replace it to collect metrics instead.
+var SelectLimitedHandler = func(table, query string) {
+  Logger.Printf("mysql: unbounded select on %q limited to %d rows: %s", table, MaxSelectLimit, query)
+}
+
+// Returns a copy of the options of `Select(...)` with the limit set to 
+// `MaxSelectLimit`, and whether it was set.
+func strict_options(options map[string]interface{}) (map[string]interface{}, bool) {
+  if MaxSelectLimit <= 0 { return options, false }
+  if _, ok := options["limit"].(int); ok { return options, false }
+  if unbounded, _ := options["unbounded"].(bool); unbounded { return options, false }
+
+  limited := map[string]interface{}{"limit": MaxSelectLimit}
+  for key, value := range options {
+    if key != "limit" { limited[key] = value }
+  }
+  return limited, true
+}
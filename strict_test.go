@@ -0,0 +1,55 @@
+package mysql_test
+
+import (
+	"reflect"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestMaxSelectLimit(t *testing.T) {
+  defer func(limit int, handler func(string, string)) {
+    mysql.MaxSelectLimit, mysql.SelectLimitedHandler = limit, handler
+  }(mysql.MaxSelectLimit, mysql.SelectLimitedHandler)
+  mysql.MaxSelectLimit = 5
+  var limited []string
+  mysql.SelectLimitedHandler = func(table, query string) { limited = append(limited, query) }
+
+  tests := []struct {
+    name    string
+    run     func()
+    queries []string
+    limited []string
+  }{
+    {
+      "select", func() { mysql.Select("events", nil) },
+      []string{"SELECT * FROM `events` LIMIT 0, 5;"}, []string{"SELECT * FROM `events` LIMIT 0, 5"},
+    },
+    {
+      "select with a limit", func() { mysql.Select("events", nil, map[string]interface{}{"limit": 10}) },
+      []string{"SELECT * FROM `events` LIMIT 0, 10;"}, nil,
+    },
+    {
+      "unbounded select", func() { mysql.Select("events", nil, map[string]interface{}{"unbounded": true}) },
+      []string{"SELECT * FROM `events`;"}, nil,
+    },
+    {
+      "select each", func() {
+        mysql.SelectEach("events", nil, nil, func(map[string]interface{}) error { return nil })
+      },
+      []string{"SELECT * FROM `events`;"}, nil,
+    },
+    {"build select", func() { mysql.BuildSelect("events", nil) }, []string{}, nil},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      mock := mysqltest.New(t)
+      limited = nil
+      test.run()
+      if got := mock.SQL(); !reflect.DeepEqual(got, test.queries) { t.Errorf("queries = %q, want %q", got, test.queries) }
+      if !reflect.DeepEqual(limited, test.limited) { t.Errorf("limited queries = %q, want %q", limited, test.limited) }
+    })
+  }
+}
@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Tags prepended to every query as a sqlcommenter style comment, e.g. 
+// `/* app='checkout' */ SELECT ...`, so slow query logs and
+// `performance_schema` can be attributed to the application. Tags of the 
+// context, see `WithQueryTags(...)`, and of the option `"tags": 
+// map[string]string` are added to them.
+//
+// Example:
+//   mysql.QueryTags = map[string]string{"app": "checkout"}
+var QueryTags map[string]string
+
+type tags_key struct{}
+
+// Returns a context carrying tags of the queries of handles using it, added 
+// to the tags of `ctx` and `QueryTags`, see `WithContext(...)`.
+//
+// Example:
+//   func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//     ctx := mysql.WithQueryTags(r.Context(), map[string]string{"route": r.URL.Path})
+//     // /* app='checkout',route='%2Forders' */ SELECT * FROM `orders` ...
+//     rows := mysql.WithContext(ctx).Select("orders", where)
+//   }
+func WithQueryTags(ctx context.Context, tags map[string]string) context.Context {
+  merged := map[string]string{}
+  for key, value := range query_tags(ctx) { merged[key] = value }
+  for key, value := range tags { merged[key] = value }
+  return context.WithValue(ctx, tags_key{}, merged)
+}
+
+func query_tags(ctx context.Context) map[string]string {
+  tags, _ := ctx.Value(tags_key{}).(map[string]string)
+  return tags
+}
+
+// Returns the comment of the tags, or "". Keys and values are URL encoded, so 
+// they can't end the comment.
+func tag_comment(tags map[string]string) string {
+  if len(tags) == 0 { return "" }
+  keys := make([]string, 0, len(tags))
+  for key := range tags { keys = append(keys, key) }
+  sort.Strings(keys)
+
+  pairs := make([]string, len(keys))
+  for i, key := range keys {
+    pairs[i] = url.QueryEscape(key) + "='" + url.QueryEscape(tags[key]) + "'"
+  }
+  return "/* " + strings.Join(pairs, ",") + " */ "
+}
+
+// Returns the comment of the "tags" option, merged into the tags of the 
+// context by `tag_query(...)`.
+func option_tags(options map[string]interface{}) string {
+  tags, _ := options["tags"].(map[string]string)
+  return tag_comment(tags)
+}
+
+// Prepends the tags of `QueryTags` and of the context of the handle to the 
+// query, merged with the tags of a leading comment of the "tags" option.
+func (d *DB) tag_query(query string) string {
+  tags := query_tags(d.context())
+  if len(QueryTags) == 0 && len(tags) == 0 { return query }
+
+  merged := map[string]string{}
+  for key, value := range QueryTags { merged[key] = value }
+  for key, value := range tags { merged[key] = value }
+  if end := strings.Index(query, " */ "); strings.HasPrefix(query, "/* ") && end > 0 {
+    if option := parse_tags(query[3:end]); option != nil {
+      for key, value := range option { merged[key] = value }
+      query = query[end+4:]
+    }
+  }
+  return tag_comment(merged) + query
+}
+
+// Parses the content of a comment of `tag_comment(...)`, or returns nil when 
+// it's another comment.
+func parse_tags(comment string) map[string]string {
+  tags := map[string]string{}
+  for _, pair := range strings.Split(comment, ",") {
+    key, value, found := strings.Cut(pair, "=")
+    if !found || len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+      return nil
+    }
+    key, err := url.QueryUnescape(key)
+    if err != nil { return nil }
+    if value, err = url.QueryUnescape(value[1:len(value)-1]); err != nil { return nil }
+    tags[key] = value
+  }
+  return tags
+}
@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Routes the tables of a tenant, see `WithTenant(...)`. It's `TenantPrefix` by 
+// default, set it to `TenantSchema` for a database per tenant, or to a 
+// function of another naming scheme.
+var TenantTable = TenantPrefix
+
+// Tables shared by every tenant, like "tenants" or "plans", which aren't 
+// routed. Tables qualified with a database, like "common.plans", aren't 
+// routed either.
+var SharedTables []string
+
+// Table of a tenant prefixed by its id, "acme_orders" for `orders` of "acme".
+func TenantPrefix(tenant, table string) string { return tenant + "_" + table }
+
+// Table in the database of a tenant, "acme.orders" for `orders` of "acme".
+func TenantSchema(tenant, table string) string { return tenant + "." + table }
+
+type tenant_key struct{}
+
+// Returns a context routing the tables of `Select(...)`, `Insert(...)`, 
+// `Update(...)`, `Delete(...)` and the other table methods of handles using 
+// it to a tenant, see `TenantTable` and `WithContext(...)`. The option 
+// `"tenant": "acme"` routes a single query. Tenant ids may contain letters, 
+// digits, "_" and "$".
+//
+// Tables of a SELECT renamed by the routing are aliased by their name, so 
+// qualified columns like "orders.status" keep working. Queries of `Exec(...)` 
+// and `ExecQuery(...)` aren't routed.
+//
+// Example:
+//   func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//     ctx := mysql.WithTenant(r.Context(), r.Header.Get("X-Tenant"))
+//     // SELECT * FROM `acme_orders` AS `orders` WHERE `status` = ?
+//     rows := mysql.WithContext(ctx).Select("orders", _json{"status": "paid"})
+//   }
+func WithTenant(ctx context.Context, tenant string) context.Context {
+  check_tenant(tenant)
+  return context.WithValue(ctx, tenant_key{}, tenant)
+}
+
+// Returns the tenant of a context of `WithTenant(...)`, or "".
+func TenantFromContext(ctx context.Context) string {
+  tenant, _ := ctx.Value(tenant_key{}).(string)
+  return tenant
+}
+
+func check_tenant(tenant string) {
+  for _, char := range tenant {
+    if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' ||
+      char >= '0' && char <= '9' || char == '_' || char == '$') {
+      panic(fmt.Errorf("mysql: invalid tenant %q", tenant))
+    }
+  }
+}
+
+// Returns the table of the tenant, or `table` when there is no tenant or the 
+// table isn't routed.
+func route_table(table, tenant string) string {
+  if tenant == "" || strings.Contains(table, ".") { return table }
+  for _, shared := range SharedTables {
+    if shared == table { return table }
+  }
+  return TenantTable(tenant, table)
+}
+
+// Returns the tenant of the "tenant" option.
+func option_tenant(options map[string]interface{}) string {
+  tenant, _ := options["tenant"].(string)
+  check_tenant(tenant)
+  return tenant
+}
+
+// Returns the escaped table of a FROM or JOIN clause like `escape_table(...)`, 
+// routed to the tenant and aliased by its name when it's renamed.
+func escape_tenant_table(table, tenant string) string {
+  parts := strings.Fields(table)
+  if len(parts) == 3 && strings.ToUpper(parts[1]) == "AS" {
+    parts = []string{parts[0], parts[2]}
+  }
+  if len(parts) == 0 || len(parts) > 2 { return escape_table(table) }
+
+  routed := route_table(parts[0], tenant)
+  if routed == parts[0] { return escape_table(table) }
+  alias := parts[0]
+  if len(parts) == 2 { alias = parts[1] }
+  if routed[strings.LastIndex(routed, ".")+1:] == alias { return EscapeId(routed) }
+  return EscapeId(routed) + " AS " + EscapeId(alias)
+}
+
+// Returns the table of the tenant of the handle's context.
+func (d *DB) tenant_table(table string) string {
+  return route_table(table, TenantFromContext(d.context()))
+}
+
+// Returns the options with the "tenant" option of the handle's context, 
+// unless it's given.
+func (d *DB) tenant_options(options map[string]interface{}) map[string]interface{} {
+  tenant := TenantFromContext(d.context())
+  if tenant == "" { return options }
+  if _, ok := options["tenant"]; ok { return options }
+
+  copied := map[string]interface{}{"tenant": tenant}
+  for key, value := range options { copied[key] = value }
+  return copied
+}
@@ -0,0 +1,56 @@
+package mysql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+	"github.com/je3f0o/go-jeefo-mysql/mysqltest"
+)
+
+func TestNestedTransaction(t *testing.T) {
+  failure := errors.New("failed")
+  tests := []struct {
+    name    string
+    nested  error
+    queries []string
+    err     error
+  }{
+    {"nested succeeds", nil, []string{"BEGIN", "UPDATE `a` SET `n` = ?;", "UPDATE `b` SET `n` = ?;", "COMMIT"}, nil},
+    {"nested fails", failure, []string{"BEGIN", "UPDATE `a` SET `n` = ?;", "UPDATE `b` SET `n` = ?;", "ROLLBACK"}, mysql.ErrRollbackOnly},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      mock := mysqltest.New(t)
+      err  := mysql.Transaction(context.Background(), func(ctx context.Context) error {
+        mysql.WithContext(ctx).Update("a", map[string]interface{}{"n": 1}, nil)
+        nested := mysql.Transaction(ctx, func(ctx context.Context) error {
+          mysql.WithContext(ctx).Update("b", map[string]interface{}{"n": 2}, nil)
+          return test.nested
+        })
+        if nested != test.nested { t.Fatalf("nested error = %v, want %v", nested, test.nested) }
+        return nil
+      })
+
+      if err != test.err { t.Fatalf("error = %v, want %v", err, test.err) }
+      if got := mock.SQL(); !reflect.DeepEqual(got, test.queries) {
+        t.Fatalf("queries = %q, want %q", got, test.queries)
+      }
+    })
+  }
+}
+
+func TestNestedRollbackAfterCommit(t *testing.T) {
+  mock := mysqltest.New(t)
+  tx := mysql.Begin()
+  nested := tx.Begin()
+  if err := nested.Commit(); err != nil { t.Fatal(err) }
+  if err := nested.Rollback(); err != nil { t.Fatal(err) }
+  if err := tx.Commit(); err != nil { t.Fatalf("outer commit: %v", err) }
+
+  want := []string{"BEGIN", "COMMIT"}
+  if got := mock.SQL(); !reflect.DeepEqual(got, want) { t.Fatalf("queries = %q, want %q", got, want) }
+}
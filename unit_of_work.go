@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"context"
+	"sync"
+)
+
+// Collects writes during a request and executes them together, in the order 
+// they were registered, within one transaction on `Commit()`. Deferring the 
+// writes to the end of the request keeps the transaction and its row locks 
+// short for write-heavy endpoints.
+//
+// Values are copied when a write is registered, but queries are executed 
+// only on commit, so write results like insert ids are not available.
+type UnitOfWork struct {
+  mu     sync.Mutex
+  handle *DB
+  writes []func(tx *Tx)
+}
+
+type unit_of_work_key struct{}
+
+// Starts a unit of work whose writes run on this handle, and returns a 
+// context carrying it, see `UnitOfWorkFromContext(...)`.
+//
+// Example:
+//   ctx, uow := mysql.BeginUnitOfWork(r.Context())
+//   handle(ctx) // registers writes with mysql.UnitOfWorkFromContext(ctx)
+//   if err := uow.Commit(); err != nil { ... }
+func (d *DB) BeginUnitOfWork(ctx context.Context) (context.Context, *UnitOfWork) {
+  uow := &UnitOfWork{handle: d.WithContext(ctx)}
+  return context.WithValue(ctx, unit_of_work_key{}, uow), uow
+}
+
+// Returns the unit of work carried by `ctx`, or nil.
+func UnitOfWorkFromContext(ctx context.Context) *UnitOfWork {
+  uow, _ := ctx.Value(unit_of_work_key{}).(*UnitOfWork)
+  return uow
+}
+
+// Registers an insert, see `DB.Insert`.
+func (u *UnitOfWork) Insert(table string, data map[string]interface{}) {
+  data = copy_row(data)
+  u.add(func(tx *Tx) { tx.Insert(table, data) })
+}
+
+// Registers an upsert, see `DB.Upsert`.
+func (u *UnitOfWork) Upsert(table string, data map[string]interface{}, conflict ...string) {
+  data = copy_row(data)
+  u.add(func(tx *Tx) { tx.Upsert(table, data, conflict...) })
+}
+
+// Registers an update, see `DB.Update`.
+func (u *UnitOfWork) Update(
+  table string,
+  data, where map[string]interface{},
+  options ...map[string]interface{},
+) {
+  data, where, options = copy_row(data), copy_row(where), copy_options(options)
+  u.add(func(tx *Tx) { tx.Update(table, data, where, options...) })
+}
+
+// Registers a delete, see `DB.Delete`.
+func (u *UnitOfWork) Delete(
+  table string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) {
+  where, options = copy_row(where), copy_options(options)
+  u.add(func(tx *Tx) { tx.Delete(table, where, options...) })
+}
+
+// Registers a user defined statement, see `DB.Exec`.
+func (u *UnitOfWork) Exec(query string, values ...interface{}) {
+  u.add(func(tx *Tx) { tx.Exec(query, values...) })
+}
+
+// Returns the number of registered writes.
+func (u *UnitOfWork) Len() int {
+  u.mu.Lock()
+  defer u.mu.Unlock()
+  return len(u.writes)
+}
+
+// Executes the registered writes in one transaction and clears them. Nothing 
+// is executed when no write was registered.
+func (u *UnitOfWork) Commit() (err error) {
+  u.mu.Lock()
+  writes := u.writes
+  u.writes = nil
+  u.mu.Unlock()
+  if len(writes) == 0 { return nil }
+
+  defer RecoverError(&err)
+  return u.handle.Transaction(u.handle.context(), func(ctx context.Context) error {
+    tx := TxFromContext(ctx)
+    for _, write := range writes { write(tx) }
+    return nil
+  })
+}
+
+// Drops the registered writes without executing them.
+func (u *UnitOfWork) Discard() {
+  u.mu.Lock()
+  u.writes = nil
+  u.mu.Unlock()
+}
+
+func (u *UnitOfWork) add(write func(tx *Tx)) {
+  u.mu.Lock()
+  u.writes = append(u.writes, write)
+  u.mu.Unlock()
+}
+
+func copy_options(options []map[string]interface{}) []map[string]interface{} {
+  copied := make([]map[string]interface{}, len(options))
+  for i, option := range options { copied[i] = copy_row(option) }
+  return copied
+}
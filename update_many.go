@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Updates many rows identified by `key_column` in a single statement, with a 
+// `CASE` expression per column. Rows may update different columns, columns 
+// absent from a row keep their value. Hooks run for every row.
+//
+// The statement holds every value, split very large batches to stay under 
+// `max_allowed_packet`.
+//
+// Parameters:
+//   - `table`: The name of the table
+//   - `rows`: column names and values of every row, including `key_column`
+//   - `key_column`: column identifying the rows, usually the primary key
+// Returns:
+//   - sql.Result: Result of the statement execution
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // UPDATE `products` SET
+//   //   `price` = CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `price` END,
+//   //   `stock` = CASE `id` WHEN ? THEN ? ELSE `stock` END
+//   //   WHERE `id` IN(?, ?)
+//   mysql.UpdateMany("products", []map[string]interface{}{
+//     {"id": 1, "price": 990, "stock": 3},
+//     {"id": 2, "price": 1490},
+//   }, "id")
+func (d *DB) UpdateMany(
+  table string,
+  rows []map[string]interface{},
+  key_column string,
+) sql.Result {
+  if len(rows) == 0 { return driver.RowsAffected(0) }
+
+  keys    := make([]interface{}, len(rows))
+  data    := make([]map[string]interface{}, len(rows))
+  columns := map[string]bool{}
+  for i, row := range rows {
+    key, ok := row[key_column]
+    if !ok {
+      panic(fmt.Errorf("mysql: row %d of update many is missing key column %q", i, key_column))
+    }
+    keys[i] = key
+    before_update(table, row, map[string]interface{}{key_column: key})
+
+    data[i] = model_data(table, row, false)
+    delete(data[i], key_column)
+    for column := range data[i] { columns[column] = true }
+  }
+  if len(columns) == 0 {
+    panic(fmt.Errorf("mysql: update many of %q has no columns to update", table))
+  }
+
+  names := make([]string, 0, len(columns))
+  for column := range columns { names = append(names, column) }
+  sort.Strings(names)
+
+  key := EscapeId(key_column)
+  var values []interface{}
+  sets := make([]string, len(names))
+  for i, column := range names {
+    escaped := EscapeId(column)
+    set     := escaped + " = CASE " + key
+    for j, row := range data {
+      value, ok := row[column]
+      if !ok { continue }
+      set    += " WHEN ? THEN "
+      values  = append(values, keys[j])
+      if expr, ok := value.(*Expression); ok {
+        set   += expr.query
+        values = append(values, expr.values...)
+      } else {
+        set   += "?"
+        values = append(values, value)
+      }
+    }
+    sets[i] = set + " ELSE " + escaped + " END"
+  }
+
+  w := prepare_where(model_where(table, map[string]interface{}{key_column: keys}, nil))
+  values = append(values, w.values...)
+  query := fmt.Sprintf(
+    "UPDATE %s SET %s%s;", EscapeId(d.tenant_table(table)), strings.Join(sets, ", "), w.query,
+  )
+  result := d.Exec(query, values...)
+  for i, row := range rows {
+    after_update(table, row, map[string]interface{}{key_column: keys[i]}, result)
+  }
+  return result
+}
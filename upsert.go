@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Inserts a row or updates the existing one with `INSERT ... ON DUPLICATE KEY 
+// UPDATE`. Columns of `data` which are not part of the conflict target are 
+// updated.
+//
+// MySQL resolves conflicts on any PRIMARY KEY or UNIQUE index, it has no 
+// conflict target clause. So the conflict target columns are checked against 
+// the table schema first, and it panics when no unique index consists of 
+// exactly those columns, because the intended upsert semantics would not be 
+// guaranteed.
+//
+// Parameters:
+//   - `table`: The name of the table
+//   - `data`: A map of the column names and values, must contain the conflict 
+//             target columns
+//   - `conflict`: columns of the unique index identifying the row
+// Returns:
+//   - sql.Result: Result of the statement, affected rows are 1 for an insert 
+//                 and 2 for an update
+//
+// Example:
+//   type _json map[string]interface{}
+//
+//   // UNIQUE KEY (user_id, setting)
+//   mysql.Upsert("user_settings", _json{
+//     "user_id": user_id,
+//     "setting": "theme",
+//     "value":   "dark",
+//   }, "user_id", "setting")
+func (d *DB) Upsert(
+  table string,
+  data map[string]interface{},
+  conflict ...string,
+) sql.Result {
+  if len(conflict) == 0 {
+    panic(fmt.Errorf("mysql: upsert into %q needs conflict target columns", table))
+  }
+  for _, column := range conflict {
+    if _, ok := data[column]; !ok {
+      panic(fmt.Errorf("mysql: upsert data is missing conflict column %q", column))
+    }
+  }
+  if !d.has_unique_index(d.tenant_table(table), conflict) {
+    columns := strings.Join(conflict, ", ")
+    panic(fmt.Errorf("mysql: table %q has no unique index on (%s)", table, columns))
+  }
+
+  data = model_data(table, data, true)
+  var created_at string
+  if model := ModelOf(table); model != nil { created_at = model.CreatedAt }
+
+  var updates []string
+  for column := range data {
+    if contains_string(conflict, column) || column == created_at { continue }
+    escaped := EscapeId(column)
+    updates  = append(updates, escaped+" = VALUES("+escaped+")")
+  }
+  if len(updates) == 0 {
+    // Nothing to update, keep the existing row as it is.
+    escaped := EscapeId(conflict[0])
+    updates  = append(updates, escaped+" = "+escaped)
+  }
+
+  suffix := " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+  return d.insert_into("INSERT INTO", table, data, suffix)
+}
+
+func (d *DB) has_unique_index(table string, columns []string) bool {
+  for _, index := range d.Indexes(table) {
+    if !index.Unique || len(index.Columns) != len(columns) { continue }
+    matched := true
+    for _, column := range index.Columns {
+      if !contains_string(columns, column) {
+        matched = false
+        break
+      }
+    }
+    if matched { return true }
+  }
+  return false
+}
+
+func contains_string(values []string, value string) bool {
+  for _, v := range values {
+    if v == value { return true }
+  }
+  return false
+}
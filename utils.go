@@ -1,20 +1,33 @@
 package mysql
 
 import (
+	"database/sql"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
-const layout = "2006-01-02 15:04:05.000"
+// Layouts of DATE and DATETIME values, fractional seconds are optional. 
+// RFC 3339 is the format of `time.Time` values scanned into strings.
+var datetime_layouts = []string{
+  "2006-01-02 15:04:05.999999999",
+  "2006-01-02",
+  time.RFC3339Nano,
+  "2006-01-02T15:04:05.999999999",
+}
 
-// Parse a SQL datetime string in the format "2006-01-02 15:04:05.000" and 
-// convert to a `time.Time`.
+// Parse a SQL DATE or DATETIME value and convert to a `time.Time` in UTC. 
+// Values with or without fractional seconds, date only values and `time.Time` 
+// values, scanned when `Config.ParseTime` is enabled, are accepted. The zero 
+// date "0000-00-00" is the zero `time.Time`.
 //
 // Parameters:
-//   - `value`: a string representation of a date and time
+//   - `value`: a string, []byte or `time.Time` representation of a date and 
+//              time
 // Returns:
-//   - `time.Time`: representation of the input string. If the input string is 
-//   not in the expected format, it will panic.
+//   - `time.Time`: representation of the input value. If the input value is 
+//   not in one of the expected formats, it will panic.
 //
 // Example:
 //   type _json map[string]interface{}
@@ -31,11 +44,59 @@ const layout = "2006-01-02 15:04:05.000"
 //   expires_at := mysql.ParseDatetime(data["access_token_expires_at"])
 //   // code...
 func ParseDatetime(value interface{}) time.Time {
-  t, err := time.Parse(layout, value.(string))
+  t, err := TryParseDatetime(value)
+  if err != nil { panic(err) }
+  return t
+}
+
+// Same with `ParseDatetime(...)` except it returns an error instead of 
+// panicking.
+func TryParseDatetime(value interface{}) (time.Time, error) {
+  switch value := value.(type) {
+  case time.Time:
+    return value, nil
+  case []byte:
+    return TryParseDatetime(string(value))
+  case string:
+    if t, ok := parse_datetime(value); ok { return t, nil }
+    return time.Time{}, fmt.Errorf("mysql: invalid datetime %q", value)
+  }
+  return time.Time{}, fmt.Errorf("mysql: invalid datetime %v of type %T", value, value)
+}
+
+// Same with `ParseDatetime(...)` except NULL values, `nil` or "", and the 
+// zero date are returned as an invalid `sql.NullTime` instead of panicking.
+//
+// Example:
+//   deleted_at := mysql.ParseNullTime(row["deleted_at"])
+//   if deleted_at.Valid { ... }
+func ParseNullTime(value interface{}) sql.NullTime {
+  t, err := TryParseNullTime(value)
   if err != nil { panic(err) }
   return t
 }
 
+// Same with `ParseNullTime(...)` except it returns an error instead of 
+// panicking.
+func TryParseNullTime(value interface{}) (sql.NullTime, error) {
+  if text, ok := value_string(value); value == nil || ok && text == "" {
+    return sql.NullTime{}, nil
+  }
+  t, err := TryParseDatetime(value)
+  if err != nil || t.IsZero() { return sql.NullTime{}, err }
+  return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+func parse_datetime(value string) (time.Time, bool) {
+  if strings.HasPrefix(value, "0000-00-00") { return time.Time{}, true }
+  for _, layout := range datetime_layouts {
+    if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+      return t, true
+    }
+  }
+  return time.Time{}, false
+}
+
 // Converts a string to uint32
 //
 // Parameters:
@@ -46,4 +107,73 @@ func ParseUint32(value interface{}) uint32 {
   i, err := strconv.Atoi(value.(string))
   if err != nil { panic(err) }
   return uint32(i)
-}
\ No newline at end of file
+}
+// Converts a value of a result map to int64, it panics when the value is not 
+// an integer.
+//
+// Example:
+//   user_id := mysql.ParseInt64(row["user_id"])
+func ParseInt64(value interface{}) int64 {
+  i, err := TryParseInt64(value)
+  if err != nil { panic(err) }
+  return i
+}
+
+// Same with `ParseInt64(...)` except it returns an error instead of 
+// panicking.
+func TryParseInt64(value interface{}) (int64, error) {
+  switch value := value.(type) {
+  case int64:  return value, nil
+  case int:    return int64(value), nil
+  case uint64: return int64(value), nil
+  }
+  if text, ok := value_string(value); ok { return strconv.ParseInt(text, 10, 64) }
+  return 0, fmt.Errorf("mysql: invalid integer %v of type %T", value, value)
+}
+
+// Converts a value of a result map to float64, it panics when the value is 
+// not a number. DECIMAL values lose their exact precision.
+func ParseFloat64(value interface{}) float64 {
+  f, err := TryParseFloat64(value)
+  if err != nil { panic(err) }
+  return f
+}
+
+// Same with `ParseFloat64(...)` except it returns an error instead of 
+// panicking.
+func TryParseFloat64(value interface{}) (float64, error) {
+  switch value := value.(type) {
+  case float64: return value, nil
+  case int64:   return float64(value), nil
+  case uint64:  return float64(value), nil
+  }
+  if text, ok := value_string(value); ok { return strconv.ParseFloat(text, 64) }
+  return 0, fmt.Errorf("mysql: invalid number %v of type %T", value, value)
+}
+
+// Converts a value of a result map to bool, like TINYINT(1) columns holding 
+// "0" or "1". It panics when the value is not a boolean.
+func ParseBool(value interface{}) bool {
+  b, err := TryParseBool(value)
+  if err != nil { panic(err) }
+  return b
+}
+
+// Same with `ParseBool(...)` except it returns an error instead of panicking.
+func TryParseBool(value interface{}) (bool, error) {
+  switch value := value.(type) {
+  case bool:   return value, nil
+  case int64:  return value != 0, nil
+  case uint64: return value != 0, nil
+  }
+  if text, ok := value_string(value); ok { return strconv.ParseBool(text) }
+  return false, fmt.Errorf("mysql: invalid boolean %v of type %T", value, value)
+}
+
+func value_string(value interface{}) (string, bool) {
+  switch value := value.(type) {
+  case string: return value, true
+  case []byte: return string(value), true
+  }
+  return "", false
+}
@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UUID stored as BINARY(16). It's written as its 16 bytes, so it can be used 
+// as a value of data and where maps, and scanned from BINARY(16) or from a 
+// text representation like "0190d9a4-5c1e-7a3b-8f00-12ab34cd56ef".
+//
+// Example:
+//   id := mysql.NewUUIDv7()
+//   mysql.Insert("sessions", _json{"id": id, "user_id": 1})
+//   row := mysql.First("sessions", _json{"id": id})
+type UUID [16]byte
+
+// Returns a random version 4 UUID.
+func NewUUID() UUID {
+  var u UUID
+  if _, err := rand.Read(u[:]); err != nil { panic(err) }
+  u[6] = u[6]&0x0f | 0x40
+  u[8] = u[8]&0x3f | 0x80
+  return u
+}
+
+// Returns a version 7 UUID, which starts with the current Unix time in 
+// milliseconds followed by random bits. They're ordered by creation time, so 
+// inserts as primary keys append to the index instead of splitting its pages.
+func NewUUIDv7() UUID {
+  var u UUID
+  if _, err := rand.Read(u[6:]); err != nil { panic(err) }
+  var ms [8]byte
+  binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+  copy(u[:6], ms[2:])
+  u[6] = u[6]&0x0f | 0x70
+  u[8] = u[8]&0x3f | 0x80
+  return u
+}
+
+// Parses a UUID in the canonical "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx" form 
+// or as 32 hex digits, optionally in braces or with a "urn:uuid:" prefix.
+func ParseUUID(text string) (UUID, error) {
+  var u UUID
+  digits := strings.TrimPrefix(strings.ToLower(text), "urn:uuid:")
+  if strings.HasPrefix(digits, "{") && strings.HasSuffix(digits, "}") {
+    digits = digits[1 : len(digits)-1]
+  }
+  if len(digits) == 36 {
+    if digits[8] != '-' || digits[13] != '-' || digits[18] != '-' || digits[23] != '-' {
+      return u, fmt.Errorf("mysql: invalid UUID %q", text)
+    }
+    digits = strings.Replace(digits, "-", "", 4)
+  }
+  if len(digits) != 32 { return u, fmt.Errorf("mysql: invalid UUID %q", text) }
+  if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+    return u, fmt.Errorf("mysql: invalid UUID %q", text)
+  }
+  return u, nil
+}
+
+// Same with `ParseUUID(...)` except it panics when the text is not a UUID.
+func MustParseUUID(text string) UUID {
+  u, err := ParseUUID(text)
+  if err != nil { panic(err) }
+  return u
+}
+
+// Returns the canonical form, "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx".
+func (u UUID) String() string {
+  var text [36]byte
+  hex.Encode(text[0:8], u[0:4])
+  hex.Encode(text[9:13], u[4:6])
+  hex.Encode(text[14:18], u[6:8])
+  hex.Encode(text[19:23], u[8:10])
+  hex.Encode(text[24:], u[10:])
+  text[8], text[13], text[18], text[23] = '-', '-', '-', '-'
+  return string(text[:])
+}
+
+// Returns the version of the UUID, e.g. 4 or 7.
+func (u UUID) Version() int { return int(u[6] >> 4) }
+
+// Reports whether it's the nil UUID, all zeros.
+func (u UUID) IsZero() bool { return u == UUID{} }
+
+// Value implements `driver.Valuer`, the 16 bytes of BINARY(16).
+func (u UUID) Value() (driver.Value, error) { return u[:], nil }
+
+// Scan implements `sql.Scanner` for BINARY(16) and text columns. NULL scans 
+// the nil UUID, scan into a `*UUID` to tell them apart.
+func (u *UUID) Scan(src interface{}) error {
+  switch src := src.(type) {
+  case nil:
+    *u = UUID{}
+    return nil
+  case []byte:
+    if len(src) == 16 {
+      copy(u[:], src)
+      return nil
+    }
+    return u.UnmarshalText(src)
+  case string:
+    return u.UnmarshalText([]byte(src))
+  }
+  return fmt.Errorf("mysql: cannot scan %T into UUID", src)
+}
+
+// MarshalText implements `encoding.TextMarshaler`, so UUIDs are strings in 
+// JSON.
+func (u UUID) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (u *UUID) UnmarshalText(text []byte) error {
+  parsed, err := ParseUUID(string(text))
+  if err != nil { return err }
+  *u = parsed
+  return nil
+}
+
+// Converts a value of a UUID column to the 16 bytes written to BINARY(16). 
+// Strings are parsed, other values are written as they are.
+func uuid_value(value interface{}) interface{} {
+  switch v := value.(type) {
+  case string:
+    return MustParseUUID(v)
+  case []string:
+    values := make([]interface{}, len(v))
+    for i, text := range v { values[i] = MustParseUUID(text) }
+    return values
+  }
+  return value
+}
+
+// Converts a BINARY(16) value of a result row to the canonical form, other 
+// values are returned as they are.
+func uuid_text(value interface{}) interface{} {
+  switch v := value.(type) {
+  case string:
+    if len(v) == 16 { return UUID([]byte(v)).String() }
+  case []byte:
+    if len(v) == 16 { return UUID(v).String() }
+  }
+  return value
+}
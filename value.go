@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"fmt"
+	"time"
+)
+
+// Returns a single cell, the column of the first matching row, instead of 
+// `First(...)` and a map lookup. NULL is returned as nil, other values like 
+// `Select(...)` values. It returns `ErrNoRows` when no row matches.
+//
+// Parameters:
+//   - `table`: name of the table
+//   - `column`: name of the column to return
+//   - `where`: conditions in the same format as `Select(...)`
+//   - `options`: options of `Select(...)` except columns and limit
+//
+// Example:
+//   email, err := mysql.Value("users", "email", _json{"id": id})
+func (d *DB) Value(
+  table, column string,
+  where map[string]interface{},
+  args ...map[string]interface{},
+) (interface{}, error) {
+  options := map[string]interface{}{"null_as_nil": true}
+  if len(args) > 0 {
+    for key, value := range args[0] { options[key] = value }
+  }
+  options["limit"] = 1
+
+  values, err := d.Pluck(table, column, where, options)
+  if err != nil { return nil, err }
+  if len(values) == 0 { return nil, ErrNoRows }
+  return values[0], nil
+}
+
+// Same with `Value(...)` except the cell is converted to int64, NULL is 0.
+//
+// Example:
+//   stock, err := mysql.Int64Value("products", "stock", _json{"sku": sku})
+func (d *DB) Int64Value(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (int64, error) {
+  value, err := d.Value(table, column, where, options...)
+  if err != nil || value == nil { return 0, err }
+  return TryParseInt64(value)
+}
+
+// Same with `Value(...)` except the cell is converted to string, NULL is "".
+func (d *DB) StringValue(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (string, error) {
+  value, err := d.Value(table, column, where, options...)
+  if err != nil || value == nil { return "", err }
+  if text, ok := value_string(value); ok { return text, nil }
+  return fmt.Sprint(value), nil
+}
+
+// Same with `Value(...)` except the cell is converted to `time.Time`, see 
+// `ParseDatetime(...)`. NULL and the zero date are the zero time.
+//
+// Example:
+//   last_login, err := mysql.TimeValue("users", "last_login", _json{"id": id})
+func (d *DB) TimeValue(
+  table, column string,
+  where map[string]interface{},
+  options ...map[string]interface{},
+) (time.Time, error) {
+  value, err := d.Value(table, column, where, options...)
+  if err != nil { return time.Time{}, err }
+  t, err := TryParseNullTime(value)
+  return t.Time, err
+}
@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Converts the non-zero fields of a filter struct into where conditions, so 
+// typed filter objects can be used with `Select(...)`. The `db` tag of a field 
+// is the where key, a column optionally followed by an operator like 
+// `db:"age >="`. Untagged fields use the snake case of their names, fields 
+// tagged `db:"-"` are skipped and embedded structs are flattened. Pointer 
+// fields are dereferenced when not nil, so zero values can be filtered too. 
+// Fields implementing `driver.Valuer`, like `sql.NullString`, are single 
+// values.
+//
+// Example:
+//   type UserFilter struct {
+//     Status   string    `db:"status"`
+//     MinAge   int       `db:"age >="`
+//     MaxAge   int       `db:"age <="`
+//     Roles    []string  `db:"role"` // IN when not empty
+//     Verified *bool
+//   }
+//
+//   // WHERE `status` = ? AND `age` >= ? AND `verified` = ?
+//   verified := true
+//   where    := mysql.WhereFromStruct(UserFilter{Status: "active", MinAge: 18, Verified: &verified})
+//   rows     := mysql.Select("users", where)
+func WhereFromStruct(s any) map[string]interface{} {
+  value := reflect.ValueOf(s)
+  for value.Kind() == reflect.Pointer {
+    if value.IsNil() { return map[string]interface{}{} }
+    value = value.Elem()
+  }
+  if value.Kind() != reflect.Struct {
+    panic(fmt.Errorf("mysql: WhereFromStruct expects a struct, got %T", s))
+  }
+
+  where := map[string]interface{}{}
+  struct_where(value, where)
+  return where
+}
+
+var valuer_type = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+func struct_where(value reflect.Value, where map[string]interface{}) {
+  t := value.Type()
+  for i := 0; i < t.NumField(); i++ {
+    field := t.Field(i)
+    tag   := field.Tag.Get("db")
+    if tag == "-" { continue }
+
+    v := value.Field(i)
+    if field.Anonymous && tag == "" {
+      for v.Kind() == reflect.Pointer && !v.IsNil() { v = v.Elem() }
+      if v.Kind() == reflect.Struct && !reflect.PointerTo(v.Type()).Implements(valuer_type) {
+        struct_where(v, where)
+        continue
+      }
+    }
+    if !field.IsExported() || v.IsZero() { continue }
+
+    // Pointers implementing `driver.Valuer` are kept, their method may need 
+    // the pointer receiver
+    for v.Kind() == reflect.Pointer && !v.Type().Implements(valuer_type) {
+      if v.IsNil() { break }
+      v = v.Elem()
+    }
+    _, valuer := v.Interface().(driver.Valuer)
+    if !valuer && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() == 0 {
+      continue
+    }
+
+    key := tag
+    if key == "" { key = snake_case(field.Name) }
+    where[key] = v.Interface()
+  }
+}
+
+// Converts a Go name to snake case, e.g. "UserID" to "user_id".
+func snake_case(name string) string {
+  runes := []rune(name)
+  var result strings.Builder
+  for i, r := range runes {
+    if unicode.IsUpper(r) && i > 0 {
+      previous := runes[i-1]
+      next_lower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+      if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+        (unicode.IsUpper(previous) && next_lower) {
+        result.WriteByte('_')
+      }
+    }
+    result.WriteRune(unicode.ToLower(r))
+  }
+  return result.String()
+}
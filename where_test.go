@@ -0,0 +1,72 @@
+package mysql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	mysql "github.com/je3f0o/go-jeefo-mysql"
+)
+
+type tags []string
+
+func (t tags) Value() (driver.Value, error) { return strings.Join(t, ","), nil }
+
+type counted struct{ calls *int }
+
+func (c counted) Value() (driver.Value, error) {
+  *c.calls++
+  return "value", nil
+}
+
+func TestWhereValuers(t *testing.T) {
+  var calls int
+  tests := []struct {
+    name   string
+    where  map[string]interface{}
+    query  string
+    values []interface{}
+  }{
+    {
+      "slice valuer is a single value",
+      map[string]interface{}{"tags": tags{"a", "b"}},
+      "SELECT * FROM `posts` WHERE `tags` = ?;", []interface{}{"a,b"},
+    },
+    {
+      "invalid null string is NULL",
+      map[string]interface{}{"name": sql.NullString{}},
+      "SELECT * FROM `posts` WHERE `name` IS NULL;", nil,
+    },
+    {
+      "invalid null string is not NULL",
+      map[string]interface{}{"name !=": sql.NullString{}},
+      "SELECT * FROM `posts` WHERE `name` IS NOT NULL;", nil,
+    },
+    {
+      "valid null string",
+      map[string]interface{}{"name": sql.NullString{String: "alice", Valid: true}},
+      "SELECT * FROM `posts` WHERE `name` = ?;", []interface{}{"alice"},
+    },
+    {
+      "nil pointer valuer is NULL",
+      map[string]interface{}{"name": (*sql.NullString)(nil)},
+      "SELECT * FROM `posts` WHERE `name` IS NULL;", nil,
+    },
+    {
+      "valuer is resolved once",
+      map[string]interface{}{"name": counted{&calls}},
+      "SELECT * FROM `posts` WHERE `name` = ?;", []interface{}{"value"},
+    },
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      query, values := mysql.BuildSelect("posts", test.where)
+      if query != test.query { t.Errorf("query = %q, want %q", query, test.query) }
+      if !reflect.DeepEqual(values, test.values) { t.Errorf("values = %#v, want %#v", values, test.values) }
+    })
+  }
+  if calls != 1 { t.Errorf("Value() called %d times, want 1", calls) }
+}
@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+)
+
+// Runs `fn` and converts panics of this package into an error, so existing 
+// panic-based code can migrate to returned errors incrementally. Errors are 
+// logged to `Logger`, a failed query is returned as `*Error` with its query. 
+// Other panics, like runtime errors, are propagated.
+//
+// Example:
+//   err := mysql.WrapFunc(func() error {
+//     user := mysql.First("users", _json{"id": id})
+//     mysql.Update("users", _json{"visits": mysql.Raw("visits + 1")}, _json{"id": id})
+//     return nil
+//   })
+func WrapFunc(fn func() error) (err error) {
+  defer func() {
+    if r := recover(); r != nil { err = recovered_error(r) }
+  }()
+  return fn()
+}
+
+// Wraps an `http.Handler` converting panics of this package into a response 
+// with status 500, or 504 when the request context ran out of time, after 
+// logging them like `WrapFunc(...)`.
+//
+// Example:
+//   http.Handle("/users", mysql.WrapHandler(users_handler))
+func WrapHandler(handler http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    err := WrapFunc(func() error {
+      handler.ServeHTTP(w, r)
+      return nil
+    })
+    if err == nil { return }
+
+    status := http.StatusInternalServerError
+    if errors.Is(err, context.DeadlineExceeded) ||
+      errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+      status = http.StatusGatewayTimeout
+    }
+    http.Error(w, http.StatusText(status), status)
+  })
+}
+
+// Converts a recovered panic of this package into an error and logs it, 
+// other panics are propagated.
+func recovered_error(r interface{}) error {
+  switch e := r.(type) {
+  case runtime.Error:
+    panic(r)
+  case error:
+    Logger.Println(e)
+    return e
+  }
+  panic(r)
+}